@@ -0,0 +1,264 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var accountableCommand = cli.Command{
+	Name:  "accountable",
+	Usage: "sign with a ring while letting an opening authority reveal the signer",
+	Subcommands: []cli.Command{
+		{
+			Name:  "sign",
+			Usage: "sign a message and encrypt your identity to the opening authority",
+			UsageText: "ring-signatures accountable sign --message \"hello!\" --private-key Pr1v4T3k3y" +
+				" --ring-name team --opener 0p3n3r",
+			Action: accountableSign,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message to sign",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "private key to use for signing",
+				},
+				cli.StringSliceFlag{
+					Name:  "ring, r",
+					Usage: "comma-separated list of public keys (or fingerprint prefixes of saved ring members) to use as ring",
+				},
+				cli.StringFlag{
+					Name:  "ring-jwks",
+					Usage: "JSON Web Key Set file containing the public keys to use as ring",
+				},
+				cli.StringFlag{
+					Name:  "ring-name, n",
+					Usage: "name of a saved ring to use (see the ring command)",
+				},
+				cli.StringFlag{
+					Name:  "opener, o",
+					Usage: "public key of the authority that can reveal the signer",
+				},
+			},
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify an accountable signature",
+			UsageText: "ring-signatures accountable verify --message \"hello!\" --signature s1GN4tUr3 --opener 0p3n3r",
+			Action:    accountableVerify,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to verify",
+				},
+				cli.StringFlag{
+					Name:  "ring-name, n",
+					Usage: "name of a saved ring the signature must have been produced with",
+				},
+				cli.StringFlag{
+					Name:  "opener, o",
+					Usage: "public key of the opening authority the signature must be openable by",
+				},
+			},
+		},
+		{
+			Name:      "open",
+			Usage:     "reveal the signer of an accountable signature",
+			UsageText: "ring-signatures accountable open --message \"hello!\" --signature s1GN4tUr3 --private-key 0p3n3rK3y",
+			Action:    accountableOpen,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to open",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "private key of the opening authority",
+				},
+			},
+		},
+		{
+			Name:      "check-opening",
+			Usage:     "check that an opening reveals the actual signer",
+			UsageText: "ring-signatures accountable check-opening --message \"hello!\" --signature s1GN4tUr3 --opening 0p3n1nG",
+			Action:    accountableCheckOpening,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "opened signature",
+				},
+				cli.StringFlag{
+					Name:  "opening",
+					Usage: "opening produced by the opening authority",
+				},
+			},
+		},
+	},
+}
+
+// accountableSignatureFromFlags decodes the signature given in the command flags.
+func accountableSignatureFromFlags(c *cli.Context) (*ring.AccountableSignature, error) {
+	sigStr := c.String("signature")
+	if len(sigStr) == 0 {
+		return nil, cli.NewExitError("you need to specify the signature", 1)
+	}
+
+	sig := &ring.AccountableSignature{}
+	err := sig.Decode(sigStr)
+	if err != nil {
+		return nil, cli.NewExitError("invalid signature", 1)
+	}
+
+	return sig, nil
+}
+
+func accountableSign(c *cli.Context) error {
+	ringKeys, err := ringFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	m := c.String("message")
+	if len(m) == 0 {
+		return cli.NewExitError("you need to specify a message to sign", 1)
+	}
+
+	sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil || len(sk) == 0 {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	opener, err := ring.ConfigDecodeKey(c.String("opener"))
+	if err != nil || len(opener) == 0 {
+		return cli.NewExitError("invalid opener public key", 1)
+	}
+
+	privKey := ring.PrivateKey(sk)
+	i, err := privKey.SignerIndex(ringKeys)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sig, err := privKey.SignAccountable(crand.Reader, []byte(m), ringKeys, i, ring.PublicKey(opener))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sigStr, err := sig.Encode()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(sigStr)
+
+	return nil
+}
+
+func accountableVerify(c *cli.Context) error {
+	sig, err := accountableSignatureFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	m := c.String("message")
+	if len(m) == 0 {
+		return cli.NewExitError("you need to specify the signed message", 1)
+	}
+
+	if !sig.Verify([]byte(m)) {
+		return cli.NewExitError(ring.ErrInvalidSignature, 1)
+	}
+
+	if o := c.String("opener"); len(o) > 0 {
+		opener, err := ring.ConfigDecodeKey(o)
+		if err != nil || string(opener) != string(sig.Opener()) {
+			return cli.NewExitError("the signature can't be opened by the expected opener", 1)
+		}
+	}
+
+	if len(c.String("ring-name")) > 0 {
+		expected, err := ringFromFlags(c)
+		if err != nil {
+			return err
+		}
+
+		if !ring.NewKeySet(sig.Ring()...).Equal(ring.NewKeySet(expected...)) {
+			return cli.NewExitError("signature was not produced with the expected ring", 1)
+		}
+	}
+
+	fmt.Println("Signature is valid.")
+	fmt.Printf("Opener: %s\n", fingerprint(sig.Opener()))
+
+	return nil
+}
+
+func accountableOpen(c *cli.Context) error {
+	sig, err := accountableSignatureFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	if !sig.Verify([]byte(c.String("message"))) {
+		return cli.NewExitError(ring.ErrInvalidSignature, 1)
+	}
+
+	sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil || len(sk) == 0 {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	opening, err := ring.PrivateKey(sk).Open(crand.Reader, sig)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	encoded, err := opening.Encode()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Signer: %s\n", ring.ConfigEncodeKey(opening.Signer()))
+	fmt.Printf("Fingerprint: %s\n", fingerprint(opening.Signer()))
+	fmt.Printf("Opening: %s\n", encoded)
+
+	return nil
+}
+
+func accountableCheckOpening(c *cli.Context) error {
+	sig, err := accountableSignatureFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	opening := &ring.Opening{}
+	err = opening.Decode(c.String("opening"))
+	if err != nil {
+		return cli.NewExitError("invalid opening", 1)
+	}
+
+	if !opening.Verify(sig, []byte(c.String("message"))) {
+		return cli.NewExitError("the opening doesn't match a valid signature of the message", 1)
+	}
+
+	fmt.Printf("Signer: %s\n", ring.ConfigEncodeKey(opening.Signer()))
+	fmt.Printf("Fingerprint: %s\n", fingerprint(opening.Signer()))
+
+	return nil
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var benchCommand = cli.Command{
+	Name:      "bench",
+	Usage:     "measure the performance of signature schemes",
+	UsageText: "ring-signatures bench --backend schnorr --backend lattice --ring-size 10 --ring-size 100 --format json",
+	Action:    bench,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "backend, b",
+			Usage: "signature scheme to measure (" + strings.Join(benchBackendNames(), ", ") + "; all when omitted)",
+		},
+		cli.IntSliceFlag{
+			Name:  "ring-size, r",
+			Usage: "ring size to measure (2, 10 and 100 when omitted)",
+		},
+		cli.IntFlag{
+			Name:  "iterations, n",
+			Usage: "number of signatures to produce and verify for each measure",
+			Value: 5,
+		},
+		cli.IntFlag{
+			Name:  "batch",
+			Usage: "number of signatures verified in parallel to measure throughput",
+			Value: 64,
+		},
+		cli.StringFlag{
+			Name:  "format, f",
+			Usage: "output format: table or json",
+			Value: "table",
+		},
+	},
+}
+
+// benchSignature is a signature produced by a measured scheme.
+type benchSignature interface {
+	Verify(message []byte) bool
+	Encode() (string, error)
+}
+
+// benchRing signs with a ring of a measured scheme and decodes the
+// signatures it produces.
+type benchRing struct {
+	sign   func(message []byte) (benchSignature, error)
+	decode func(encoded string) (benchSignature, error)
+}
+
+// benchBackend is a signature scheme measured by the bench command.
+type benchBackend struct {
+	// keygen generates a key pair.
+	keygen func() error
+
+	// setup generates a ring of the given size.
+	setup func(ringSize int) (*benchRing, error)
+}
+
+// compactBenchSignature binds a compact signature to its ring, which it
+// doesn't contain.
+type compactBenchSignature struct {
+	*ring.CompactSignature
+	ringKeys []ring.PublicKey
+}
+
+func (sig *compactBenchSignature) Verify(message []byte) bool {
+	return sig.CompactSignature.Verify(message, sig.ringKeys)
+}
+
+// generateRing generates a ring of elliptic curve keys.
+func generateRing(ringSize int) ([]ring.PublicKey, ring.PrivateKey) {
+	ringKeys := make([]ring.PublicKey, ringSize)
+	var sk ring.PrivateKey
+	for i := range ringKeys {
+		ringKeys[i], sk = ring.Generate(crand.Reader)
+	}
+
+	return ringKeys, sk
+}
+
+var benchBackends = map[string]benchBackend{
+	"schnorr": {
+		keygen: func() error {
+			ring.Generate(crand.Reader)
+			return nil
+		},
+		setup: func(ringSize int) (*benchRing, error) {
+			ringKeys, sk := generateRing(ringSize)
+			return &benchRing{
+				sign: func(message []byte) (benchSignature, error) {
+					return sk.Sign(crand.Reader, message, ringKeys, ringSize-1)
+				},
+				decode: func(encoded string) (benchSignature, error) {
+					sig := &ring.Signature{}
+					return sig, sig.Decode(encoded)
+				},
+			}, nil
+		},
+	},
+	"linkable": {
+		keygen: func() error {
+			ring.Generate(crand.Reader)
+			return nil
+		},
+		setup: func(ringSize int) (*benchRing, error) {
+			ringKeys, sk := generateRing(ringSize)
+			return &benchRing{
+				sign: func(message []byte) (benchSignature, error) {
+					return sk.SignLinkable(crand.Reader, message, ringKeys, ringSize-1, []byte("bench"))
+				},
+				decode: func(encoded string) (benchSignature, error) {
+					sig := &ring.LinkableSignature{}
+					return sig, sig.Decode(encoded)
+				},
+			}, nil
+		},
+	},
+	"compact": {
+		keygen: func() error {
+			ring.Generate(crand.Reader)
+			return nil
+		},
+		setup: func(ringSize int) (*benchRing, error) {
+			ringKeys, sk := generateRing(ringSize)
+			return &benchRing{
+				sign: func(message []byte) (benchSignature, error) {
+					sig, err := sk.SignCompact(crand.Reader, message, ringKeys, ringSize-1)
+					if err != nil {
+						return nil, err
+					}
+
+					return &compactBenchSignature{CompactSignature: sig, ringKeys: ringKeys}, nil
+				},
+				decode: func(encoded string) (benchSignature, error) {
+					sig := &ring.CompactSignature{}
+					return &compactBenchSignature{CompactSignature: sig, ringKeys: ringKeys}, sig.Decode(encoded)
+				},
+			}, nil
+		},
+	},
+	"rsa": {
+		keygen: func() error {
+			_, err := rsa.GenerateKey(crand.Reader, 2048)
+			return err
+		},
+		setup: func(ringSize int) (*benchRing, error) {
+			ringKeys := make([]*rsa.PublicKey, ringSize)
+			var sk *rsa.PrivateKey
+			for i := range ringKeys {
+				var err error
+				sk, err = rsa.GenerateKey(crand.Reader, 2048)
+				if err != nil {
+					return nil, err
+				}
+
+				ringKeys[i] = &sk.PublicKey
+			}
+
+			return &benchRing{
+				sign: func(message []byte) (benchSignature, error) {
+					return ring.SignRSA(crand.Reader, message, ringKeys, ringSize-1, sk)
+				},
+				decode: func(encoded string) (benchSignature, error) {
+					sig := &ring.RSASignature{}
+					return sig, sig.Decode(encoded)
+				},
+			}, nil
+		},
+	},
+	"lattice": {
+		keygen: func() error {
+			ring.GenerateLattice(crand.Reader)
+			return nil
+		},
+		setup: func(ringSize int) (*benchRing, error) {
+			ringKeys := make([]ring.LatticePublicKey, ringSize)
+			var sk ring.LatticePrivateKey
+			for i := range ringKeys {
+				ringKeys[i], sk = ring.GenerateLattice(crand.Reader)
+			}
+
+			return &benchRing{
+				sign: func(message []byte) (benchSignature, error) {
+					return sk.Sign(crand.Reader, message, ringKeys, ringSize-1)
+				},
+				decode: func(encoded string) (benchSignature, error) {
+					sig := &ring.LatticeSignature{}
+					return sig, sig.Decode(encoded)
+				},
+			}, nil
+		},
+	},
+}
+
+// benchBackendNames returns the names of the measured schemes.
+func benchBackendNames() []string {
+	var names []string
+	for name := range benchBackends {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// benchResult contains the measures of a scheme for a ring size.
+// Durations are averages in nanoseconds.
+type benchResult struct {
+	Backend         string        `json:"backend"`
+	RingSize        int           `json:"ringSize"`
+	Keygen          time.Duration `json:"keygenNs"`
+	Sign            time.Duration `json:"signNs"`
+	Verify          time.Duration `json:"verifyNs"`
+	EncodedSize     int           `json:"encodedSizeBytes"`
+	BatchThroughput float64       `json:"batchVerifyPerSecond"`
+}
+
+// benchReport contains the measures and the environment they were taken in.
+type benchReport struct {
+	GoVersion string         `json:"goVersion"`
+	OS        string         `json:"os"`
+	Arch      string         `json:"arch"`
+	CPUs      int            `json:"cpus"`
+	Results   []*benchResult `json:"results"`
+}
+
+func bench(c *cli.Context) error {
+	backends := c.StringSlice("backend")
+	if len(backends) == 0 {
+		backends = benchBackendNames()
+	}
+
+	for _, name := range backends {
+		if _, ok := benchBackends[name]; !ok {
+			return cli.NewExitError(fmt.Sprintf("unknown backend: %s", name), 1)
+		}
+	}
+
+	// Duplicates are removed: the flag parser may repeat values given
+	// with the short flag name.
+	var ringSizes []int
+	seen := make(map[int]bool)
+	for _, size := range c.IntSlice("ring-size") {
+		if size < 2 {
+			return cli.NewExitError(ring.ErrRingTooSmall, 1)
+		}
+
+		if !seen[size] {
+			seen[size] = true
+			ringSizes = append(ringSizes, size)
+		}
+	}
+
+	if len(ringSizes) == 0 {
+		ringSizes = []int{2, 10, 100}
+	}
+
+	iterations := c.Int("iterations")
+	batch := c.Int("batch")
+	if iterations < 1 || batch < 1 {
+		return cli.NewExitError("iterations and batch should be positive", 1)
+	}
+
+	format := c.String("format")
+	if format != "table" && format != "json" {
+		return cli.NewExitError(fmt.Sprintf("unknown format: %s", format), 1)
+	}
+
+	report := &benchReport{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.GOMAXPROCS(0),
+	}
+
+	for _, name := range backends {
+		for _, size := range ringSizes {
+			if format == "table" {
+				fmt.Fprintf(os.Stderr, "Measuring %s with %d members...\n", name, size)
+			}
+
+			res, err := benchRun(name, size, iterations, batch)
+			if err != nil {
+				return cli.NewExitError(fmt.Sprintf("%s with %d members: %s", name, size, err), 1)
+			}
+
+			report.Results = append(report.Results, res)
+		}
+	}
+
+	if format == "json" {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Printf("%s %s/%s, %d CPUs\n", report.GoVersion, report.OS, report.Arch, report.CPUs)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BACKEND\tRING\tKEYGEN\tSIGN\tVERIFY\tSIZE\tBATCH VERIFY/S")
+	for _, res := range report.Results {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d B\t%.1f\n",
+			res.Backend,
+			res.RingSize,
+			res.Keygen.Round(time.Microsecond),
+			res.Sign.Round(time.Microsecond),
+			res.Verify.Round(time.Microsecond),
+			res.EncodedSize,
+			res.BatchThroughput,
+		)
+	}
+
+	return w.Flush()
+}
+
+// benchRun measures a scheme for a ring size.
+func benchRun(name string, ringSize, iterations, batch int) (*benchResult, error) {
+	backend := benchBackends[name]
+	res := &benchResult{Backend: name, RingSize: ringSize}
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		if err := backend.keygen(); err != nil {
+			return nil, err
+		}
+	}
+
+	res.Keygen = time.Since(start) / time.Duration(iterations)
+
+	r, err := backend.setup(ringSize)
+	if err != nil {
+		return nil, err
+	}
+
+	message := []byte("Benchmark me like the french people do.")
+	sigs := make([]benchSignature, iterations)
+
+	start = time.Now()
+	for i := range sigs {
+		sigs[i], err = r.sign(message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res.Sign = time.Since(start) / time.Duration(iterations)
+
+	start = time.Now()
+	for _, sig := range sigs {
+		if !sig.Verify(message) {
+			return nil, ring.ErrInvalidSignature
+		}
+	}
+
+	res.Verify = time.Since(start) / time.Duration(iterations)
+
+	encoded := make([]string, iterations)
+	for i, sig := range sigs {
+		encoded[i], err = sig.Encode()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res.EncodedSize = len(encoded[0])
+
+	res.BatchThroughput, err = benchBatchVerify(r, message, encoded, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// benchBatchVerify decodes and verifies a batch of signatures with one
+// worker per CPU and returns the number of signatures verified per second.
+func benchBatchVerify(r *benchRing, message []byte, encoded []string, batch int) (float64, error) {
+	jobs := make(chan string)
+	errs := make(chan error, batch)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				sig, err := r.decode(e)
+				if err != nil {
+					errs <- err
+				} else if !sig.Verify(message) {
+					errs <- ring.ErrInvalidSignature
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < batch; i++ {
+		jobs <- encoded[i%len(encoded)]
+	}
+
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return 0, err
+	}
+
+	return float64(batch) / elapsed.Seconds(), nil
+}
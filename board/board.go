@@ -0,0 +1,362 @@
+// Package board implements an append-only bulletin board of ring-signed
+// posts.
+//
+// Posts are signed by a member of the board's ring, so readers know that a
+// member wrote them without knowing which one. Every post is appended to a
+// Merkle-tree log (RFC 6962): readers who remember a tree head can later
+// check that their posts are still in the log and that the log was only
+// appended to.
+package board
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+var (
+	// ErrBoardExists is returned when creating a board that already exists.
+	ErrBoardExists = errors.New("a board already exists in that directory")
+
+	// ErrBoardNotFound is returned when a board doesn't exist.
+	ErrBoardNotFound = errors.New("board not found")
+
+	// ErrInvalidPost is returned when a post's signature is invalid or
+	// wasn't produced by the board's ring.
+	ErrInvalidPost = errors.New("the post was not signed by the board's ring")
+)
+
+const (
+	configFile = "board.json"
+	logFile    = "posts.log"
+)
+
+// Post is a ring-signed message.
+type Post struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// leafHash returns the hash of the post in the log.
+func (p *Post) leafHash() []byte {
+	encoded, _ := json.Marshal(p)
+	return HashLeaf(encoded)
+}
+
+// Verify verifies that the post was signed by the given ring.
+func (p *Post) Verify(ringKeys []ring.PublicKey) error {
+	// Only the canonical encoding is accepted, so that a post can't be
+	// published again under another encoding.
+	opts := ring.DefaultDecodeOptions
+	opts.StrictEncoding = true
+
+	sig := &ring.Signature{}
+	err := sig.DecodeWithOptions(p.Signature, opts)
+	if err != nil {
+		return ErrInvalidPost
+	}
+
+	if !sig.VerifyWithRing([]byte(p.Message), ringKeys) {
+		return ErrInvalidPost
+	}
+
+	return nil
+}
+
+// TreeHead identifies a version of the log.
+type TreeHead struct {
+	Size int    `json:"size"`
+	Root []byte `json:"root"`
+}
+
+// boardFile is the on-disk representation of a board's configuration.
+type boardFile struct {
+	Ring []string `json:"ring"`
+}
+
+// Board is a bulletin board stored in a local directory.
+type Board struct {
+	dir    string
+	ring   []ring.PublicKey
+	posts  []*Post
+	leaves [][]byte
+}
+
+// Create creates a board whose posts must be signed by the given ring.
+// The ring is put in canonical order.
+func Create(dir string, ringKeys []ring.PublicKey) (*Board, error) {
+	if _, err := os.Stat(filepath.Join(dir, configFile)); err == nil {
+		return nil, ErrBoardExists
+	}
+
+	canonical := ring.CanonicalRing(ringKeys)
+	if len(canonical) < 2 {
+		return nil, ring.ErrRingTooSmall
+	}
+
+	if len(canonical) > ring.MaxRingSize {
+		return nil, ring.ErrRingTooLarge
+	}
+
+	f := boardFile{Ring: make([]string, len(canonical))}
+	for i, k := range canonical {
+		f.Ring[i] = ring.ConfigEncodeKey(k)
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, configFile), b, 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &Board{dir: dir, ring: canonical}, nil
+}
+
+// Open loads the board stored in the given directory.
+func Open(dir string) (*Board, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, configFile))
+	if os.IsNotExist(err) {
+		return nil, ErrBoardNotFound
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var f boardFile
+	err = json.Unmarshal(b, &f)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid board file")
+	}
+
+	board := &Board{dir: dir, ring: make([]ring.PublicKey, len(f.Ring))}
+	for i, k := range f.Ring {
+		pk, err := ring.ConfigDecodeKey(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid key in board ring")
+		}
+
+		board.ring[i] = pk
+	}
+
+	err = board.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+// load reads the posts from the log file.
+func (b *Board) load() error {
+	f, err := os.Open(filepath.Join(b.dir, logFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			p := &Post{}
+			if err := json.Unmarshal(line, p); err != nil {
+				return errors.Wrapf(err, "invalid post %d", len(b.posts))
+			}
+
+			b.posts = append(b.posts, p)
+			b.leaves = append(b.leaves, p.leafHash())
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+// Ring returns the public keys allowed to post on the board.
+func (b *Board) Ring() []ring.PublicKey {
+	return b.ring
+}
+
+// Size returns the number of posts.
+func (b *Board) Size() int {
+	return len(b.posts)
+}
+
+// Posts returns all the posts, oldest first.
+func (b *Board) Posts() []*Post {
+	return b.posts
+}
+
+// Get returns the post at the given index.
+func (b *Board) Get(index int) (*Post, error) {
+	if index < 0 || index >= len(b.posts) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	return b.posts[index], nil
+}
+
+// Head returns the current tree head.
+func (b *Board) Head() *TreeHead {
+	return &TreeHead{Size: len(b.leaves), Root: RootHash(b.leaves)}
+}
+
+// Append verifies a post and appends it to the log.
+// It returns the index of the post.
+func (b *Board) Append(p *Post) (int, error) {
+	err := p.Verify(b.ring)
+	if err != nil {
+		return 0, err
+	}
+
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(b.dir, logFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	defer f.Close()
+
+	_, err = f.Write(append(encoded, '\n'))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	b.posts = append(b.posts, p)
+	b.leaves = append(b.leaves, p.leafHash())
+	return len(b.posts) - 1, nil
+}
+
+// Post signs a message with the board's ring and appends it to the log.
+func (b *Board) Post(rand io.Reader, sk ring.PrivateKey, message string) (int, error) {
+	sig, err := sk.SignAuto(rand, []byte(message), b.ring)
+	if err != nil {
+		return 0, err
+	}
+
+	encoded, err := sig.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	return b.Append(&Post{Message: message, Signature: encoded})
+}
+
+// Verify verifies the signature of the post at the given index.
+func (b *Board) Verify(index int) error {
+	p, err := b.Get(index)
+	if err != nil {
+		return err
+	}
+
+	return p.Verify(b.ring)
+}
+
+// InclusionProof proves that a post is included in a version of the log.
+type InclusionProof struct {
+	Index    int      `json:"index"`
+	Size     int      `json:"size"`
+	LeafHash []byte   `json:"leaf_hash"`
+	Root     []byte   `json:"root"`
+	Path     [][]byte `json:"path"`
+}
+
+// Verify verifies the proof.
+func (p *InclusionProof) Verify() error {
+	return VerifyInclusion(p.LeafHash, p.Index, p.Size, p.Path, p.Root)
+}
+
+// Matches returns true if the proof is for the given post.
+func (p *InclusionProof) Matches(post *Post) bool {
+	return bytes.Equal(p.LeafHash, post.leafHash())
+}
+
+// ProveInclusion proves that the post at the given index is included in
+// the log of the given size. A size of 0 means the current size.
+func (b *Board) ProveInclusion(index, size int) (*InclusionProof, error) {
+	if size == 0 {
+		size = len(b.leaves)
+	}
+
+	if index < 0 || size < 0 || size > len(b.leaves) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	path, err := InclusionPath(b.leaves[:size], index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InclusionProof{
+		Index:    index,
+		Size:     size,
+		LeafHash: b.leaves[index],
+		Root:     RootHash(b.leaves[:size]),
+		Path:     path,
+	}, nil
+}
+
+// ConsistencyProof proves that a version of the log is a prefix of a
+// later version.
+type ConsistencyProof struct {
+	OldSize int      `json:"old_size"`
+	OldRoot []byte   `json:"old_root"`
+	Size    int      `json:"size"`
+	Root    []byte   `json:"root"`
+	Path    [][]byte `json:"path"`
+}
+
+// Verify verifies the proof.
+func (p *ConsistencyProof) Verify() error {
+	return VerifyConsistency(p.OldSize, p.Size, p.OldRoot, p.Root, p.Path)
+}
+
+// ProveConsistency proves that the log of size oldSize is a prefix of
+// the log of the given size. A size of 0 means the current size.
+func (b *Board) ProveConsistency(oldSize, size int) (*ConsistencyProof, error) {
+	if size == 0 {
+		size = len(b.leaves)
+	}
+
+	if oldSize < 0 || size < 0 || size > len(b.leaves) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	path, err := ConsistencyPath(b.leaves[:size], oldSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsistencyProof{
+		OldSize: oldSize,
+		OldRoot: RootHash(b.leaves[:oldSize]),
+		Size:    size,
+		Root:    RootHash(b.leaves[:size]),
+		Path:    path,
+	}, nil
+}
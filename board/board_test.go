@@ -0,0 +1,137 @@
+package board
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+func TestBoard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "board")
+	assert.NoError(t, err, "ioutil.TempDir()")
+	defer os.RemoveAll(dir)
+
+	alicePub, alicePriv := ring.Generate(nil)
+	bobPub, bobPriv := ring.Generate(nil)
+	carolPub, carolPriv := ring.Generate(nil)
+	staff := []ring.PublicKey{alicePub, bobPub, carolPub}
+
+	t.Run("Create and open", func(t *testing.T) {
+		_, err := Create(dir, staff[:1])
+		assert.Equal(t, ring.ErrRingTooSmall, err)
+
+		// Posts couldn't be decoded with larger rings.
+		large := make([]ring.PublicKey, ring.MaxRingSize+1)
+		for i := range large {
+			large[i], _ = ring.Generate(nil)
+		}
+
+		_, err = Create(dir, large)
+		assert.Equal(t, ring.ErrRingTooLarge, err)
+
+		b, err := Create(dir, staff)
+		assert.NoError(t, err, "Create()")
+		assert.True(t, ring.IsCanonicalRing(b.Ring()))
+		assert.Equal(t, 0, b.Size())
+
+		_, err = Create(dir, staff)
+		assert.Equal(t, ErrBoardExists, err)
+
+		_, err = Open(filepath.Join(dir, "missing"))
+		assert.Equal(t, ErrBoardNotFound, err)
+
+		b, err = Open(dir)
+		assert.NoError(t, err, "Open()")
+		assert.Equal(t, ring.CanonicalRing(staff), b.Ring())
+	})
+
+	t.Run("Post and verify", func(t *testing.T) {
+		b, err := Open(dir)
+		assert.NoError(t, err, "Open()")
+
+		i, err := b.Post(nil, alicePriv, "the coffee machine is broken")
+		assert.NoError(t, err, "Post()")
+		assert.Equal(t, 0, i)
+
+		i, err = b.Post(nil, bobPriv, "the printer too")
+		assert.NoError(t, err, "Post()")
+		assert.Equal(t, 1, i)
+
+		_, outsiderPriv := ring.Generate(nil)
+		_, err = b.Post(nil, outsiderPriv, "hello")
+		assert.Equal(t, ring.ErrSignerNotInRing, err)
+
+		// Posts signed with another ring are rejected.
+		sig, err := carolPriv.SignAuto(nil, []byte("hi"), []ring.PublicKey{carolPub, alicePub})
+		assert.NoError(t, err, "SignAuto()")
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+		_, err = b.Append(&Post{Message: "hi", Signature: encoded})
+		assert.Equal(t, ErrInvalidPost, err)
+
+		// Posts whose message was changed are rejected.
+		_, err = b.Append(&Post{Message: "hi", Signature: b.Posts()[0].Signature})
+		assert.Equal(t, ErrInvalidPost, err)
+
+		// Posts can't be published again under another encoding.
+		raw, err := base64.StdEncoding.DecodeString(b.Posts()[1].Signature)
+		assert.NoError(t, err, "DecodeString()")
+		reencoded := base64.StdEncoding.EncodeToString(append([]byte(" "), raw...))
+		_, err = b.Append(&Post{Message: "the printer too", Signature: reencoded})
+		assert.Equal(t, ErrInvalidPost, err)
+
+		reopened, err := Open(dir)
+		assert.NoError(t, err, "Open()")
+		assert.Equal(t, 2, reopened.Size())
+		assert.Equal(t, b.Head(), reopened.Head())
+		assert.NoError(t, reopened.Verify(0))
+		assert.NoError(t, reopened.Verify(1))
+		assert.Equal(t, ErrIndexOutOfRange, reopened.Verify(2))
+	})
+
+	t.Run("Proofs", func(t *testing.T) {
+		b, err := Open(dir)
+		assert.NoError(t, err, "Open()")
+
+		oldHead := b.Head()
+		_, err = b.Post(nil, carolPriv, "and the lights")
+		assert.NoError(t, err, "Post()")
+
+		p, err := b.ProveInclusion(1, 0)
+		assert.NoError(t, err, "ProveInclusion()")
+		assert.Equal(t, 3, p.Size)
+		assert.NoError(t, p.Verify())
+		assert.True(t, p.Matches(b.Posts()[1]))
+		assert.False(t, p.Matches(b.Posts()[0]))
+
+		p, err = b.ProveInclusion(1, oldHead.Size)
+		assert.NoError(t, err, "ProveInclusion()")
+		assert.Equal(t, oldHead.Root, p.Root)
+		assert.NoError(t, p.Verify())
+
+		_, err = b.ProveInclusion(2, oldHead.Size)
+		assert.Equal(t, ErrIndexOutOfRange, err)
+
+		c, err := b.ProveConsistency(oldHead.Size, 0)
+		assert.NoError(t, err, "ProveConsistency()")
+		assert.Equal(t, oldHead.Root, c.OldRoot)
+		assert.Equal(t, b.Head().Root, c.Root)
+		assert.NoError(t, c.Verify())
+
+		_, err = b.ProveConsistency(4, 0)
+		assert.Equal(t, ErrIndexOutOfRange, err)
+
+		for _, args := range [][2]int{{-1, 0}, {0, -1}, {1, -2}} {
+			_, err = b.ProveInclusion(args[0], args[1])
+			assert.Equal(t, ErrIndexOutOfRange, err)
+
+			_, err = b.ProveConsistency(args[0], args[1])
+			assert.Equal(t, ErrIndexOutOfRange, err)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package board
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+)
+
+// The Merkle tree follows RFC 6962 (Certificate Transparency):
+//	* The hash of an empty tree is H()
+//	* The hash of a leaf is H(0x00 || data)
+//	* The hash of a node is H(0x01 || left || right)
+//	* A tree of n > 1 leaves is split at k, the largest power of two
+//	  smaller than n: the left subtree has k leaves, the right one n - k
+
+var (
+	// ErrInvalidProof is returned when a proof doesn't match the tree.
+	ErrInvalidProof = errors.New("invalid proof")
+
+	// ErrIndexOutOfRange is returned when a leaf index or tree size is
+	// larger than the tree.
+	ErrIndexOutOfRange = errors.New("index out of range")
+)
+
+// HashLeaf hashes leaf data.
+func HashLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// hashChildren hashes two subtrees.
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// splitPoint returns the largest power of two smaller than n (n > 1).
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+
+	return k
+}
+
+// RootHash computes the root hash of a tree from its leaf hashes.
+func RootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	default:
+		k := splitPoint(len(leaves))
+		return hashChildren(RootHash(leaves[:k]), RootHash(leaves[k:]))
+	}
+}
+
+// InclusionPath returns the audit path proving that the leaf at the
+// given index is included in the tree.
+func InclusionPath(leaves [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	return inclusionPath(leaves, index), nil
+}
+
+func inclusionPath(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+
+	k := splitPoint(len(leaves))
+	if index < k {
+		return append(inclusionPath(leaves[:k], index), RootHash(leaves[k:]))
+	}
+
+	return append(inclusionPath(leaves[k:], index-k), RootHash(leaves[:k]))
+}
+
+// VerifyInclusion verifies that a leaf is included in a tree of the
+// given size and root hash.
+func VerifyInclusion(leafHash []byte, index, size int, proof [][]byte, root []byte) error {
+	if index < 0 || index >= size {
+		return ErrIndexOutOfRange
+	}
+
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = hashChildren(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = hashChildren(r, p)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(r, root) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// ConsistencyPath returns the proof that the tree made of the first
+// oldSize leaves is a prefix of the current tree.
+func ConsistencyPath(leaves [][]byte, oldSize int) ([][]byte, error) {
+	if oldSize < 0 || oldSize > len(leaves) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	if oldSize == 0 || oldSize == len(leaves) {
+		return nil, nil
+	}
+
+	return subProof(leaves, oldSize, true), nil
+}
+
+func subProof(leaves [][]byte, m int, complete bool) [][]byte {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+
+		return [][]byte{RootHash(leaves)}
+	}
+
+	k := splitPoint(n)
+	if m <= k {
+		return append(subProof(leaves[:k], m, complete), RootHash(leaves[k:]))
+	}
+
+	return append(subProof(leaves[k:], m-k, false), RootHash(leaves[:k]))
+}
+
+// VerifyConsistency verifies that the tree of size oldSize and root
+// oldRoot is a prefix of the tree of size newSize and root newRoot.
+func VerifyConsistency(oldSize, newSize int, oldRoot, newRoot []byte, proof [][]byte) error {
+	if oldSize < 0 || oldSize > newSize {
+		return ErrIndexOutOfRange
+	}
+
+	if oldSize == newSize {
+		if len(proof) != 0 || !bytes.Equal(oldRoot, newRoot) {
+			return ErrInvalidProof
+		}
+
+		return nil
+	}
+
+	if oldSize == 0 {
+		// Every tree extends the empty tree.
+		if len(proof) != 0 {
+			return ErrInvalidProof
+		}
+
+		return nil
+	}
+
+	if len(proof) == 0 {
+		return ErrInvalidProof
+	}
+
+	// When the old tree is a complete subtree, its root is the first node.
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = hashChildren(c, fr)
+			sr = hashChildren(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = hashChildren(sr, c)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(fr, oldRoot) || !bytes.Equal(sr, newRoot) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package board
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = HashLeaf([]byte(fmt.Sprintf("leaf %d", i)))
+	}
+
+	return leaves
+}
+
+func TestRootHash(t *testing.T) {
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, empty[:], RootHash(nil))
+
+	leaves := testLeaves(3)
+	assert.Equal(t, leaves[0], RootHash(leaves[:1]))
+	assert.Equal(t, hashChildren(leaves[0], leaves[1]), RootHash(leaves[:2]))
+	assert.Equal(t, hashChildren(hashChildren(leaves[0], leaves[1]), leaves[2]), RootHash(leaves))
+
+	// Leaves and nodes are hashed differently.
+	assert.NotEqual(t, HashLeaf(append(leaves[0], leaves[1]...)), RootHash(leaves[:2]))
+}
+
+func TestInclusion(t *testing.T) {
+	leaves := testLeaves(17)
+
+	t.Run("Valid proofs", func(t *testing.T) {
+		for size := 1; size <= len(leaves); size++ {
+			root := RootHash(leaves[:size])
+			for i := 0; i < size; i++ {
+				path, err := InclusionPath(leaves[:size], i)
+				assert.NoError(t, err, "InclusionPath()")
+				assert.NoError(t, VerifyInclusion(leaves[i], i, size, path, root), "VerifyInclusion(%d, %d)", i, size)
+			}
+		}
+	})
+
+	t.Run("Invalid proofs", func(t *testing.T) {
+		root := RootHash(leaves)
+		path, err := InclusionPath(leaves, 5)
+		assert.NoError(t, err, "InclusionPath()")
+
+		assert.Equal(t, ErrInvalidProof, VerifyInclusion(leaves[6], 5, len(leaves), path, root))
+		assert.Equal(t, ErrInvalidProof, VerifyInclusion(leaves[5], 4, len(leaves), path, root))
+		assert.Equal(t, ErrInvalidProof, VerifyInclusion(leaves[5], 5, len(leaves)-1, path, root))
+		assert.Equal(t, ErrInvalidProof, VerifyInclusion(leaves[5], 5, len(leaves), path[1:], root))
+		assert.Equal(t, ErrInvalidProof, VerifyInclusion(leaves[5], 5, len(leaves), append(path, root), root))
+		assert.Equal(t, ErrInvalidProof, VerifyInclusion(leaves[5], 5, len(leaves), path, leaves[0]))
+		assert.Equal(t, ErrIndexOutOfRange, VerifyInclusion(leaves[5], len(leaves), len(leaves), path, root))
+
+		_, err = InclusionPath(leaves, len(leaves))
+		assert.Equal(t, ErrIndexOutOfRange, err)
+	})
+}
+
+func TestConsistency(t *testing.T) {
+	leaves := testLeaves(17)
+
+	t.Run("Valid proofs", func(t *testing.T) {
+		for size := 0; size <= len(leaves); size++ {
+			root := RootHash(leaves[:size])
+			for oldSize := 0; oldSize <= size; oldSize++ {
+				path, err := ConsistencyPath(leaves[:size], oldSize)
+				assert.NoError(t, err, "ConsistencyPath()")
+
+				oldRoot := RootHash(leaves[:oldSize])
+				assert.NoError(t, VerifyConsistency(oldSize, size, oldRoot, root, path), "VerifyConsistency(%d, %d)", oldSize, size)
+			}
+		}
+	})
+
+	t.Run("Invalid proofs", func(t *testing.T) {
+		root := RootHash(leaves)
+		oldRoot := RootHash(leaves[:6])
+		path, err := ConsistencyPath(leaves, 6)
+		assert.NoError(t, err, "ConsistencyPath()")
+
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(6, len(leaves), RootHash(leaves[:5]), root, path))
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(6, len(leaves), oldRoot, RootHash(leaves[:16]), path))
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(5, len(leaves), oldRoot, root, path))
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(6, len(leaves), oldRoot, root, path[1:]))
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(6, len(leaves), oldRoot, root, nil))
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(len(leaves), len(leaves), oldRoot, root, nil))
+		assert.Equal(t, ErrIndexOutOfRange, VerifyConsistency(len(leaves)+1, len(leaves), oldRoot, root, path))
+
+		// A rewritten history is detected.
+		rewritten := testLeaves(17)
+		rewritten[2] = HashLeaf([]byte("rewritten"))
+		path, err = ConsistencyPath(rewritten, 6)
+		assert.NoError(t, err, "ConsistencyPath()")
+		assert.Equal(t, ErrInvalidProof, VerifyConsistency(6, len(leaves), oldRoot, RootHash(rewritten), path))
+	})
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/t-bast/ring-signatures/board"
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/t-bast/ring-signatures/store"
+	"github.com/urfave/cli"
+)
+
+var boardCommand = cli.Command{
+	Name:  "board",
+	Usage: "post anonymously on a tamper-evident bulletin board",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dir",
+			Usage: "directory of the board (defaults to the \"board\" directory in the config dir)",
+		},
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:      "init",
+			Usage:     "create a board for the members of a ring",
+			UsageText: "ring-signatures board init --ring-name staff",
+			Action:    boardInit,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "ring, r",
+					Usage: "public keys allowed to post",
+				},
+				cli.StringFlag{
+					Name:  "ring-jwks",
+					Usage: "JSON Web Key Set file containing the public keys allowed to post",
+				},
+				cli.StringFlag{
+					Name:  "ring-name, n",
+					Usage: "name of a saved ring containing the public keys allowed to post",
+				},
+			},
+		},
+		{
+			Name:      "post",
+			Usage:     "sign a message with the board's ring and append it to the log",
+			UsageText: "ring-signatures board post --message \"hello!\" --private-key Pr1v4T3k3y",
+			Action:    boardPost,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message to post",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "private key to sign the post with",
+				},
+			},
+		},
+		{
+			Name:      "list",
+			Usage:     "list the posts",
+			UsageText: "ring-signatures board list",
+			Action:    boardList,
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify the signature of a post",
+			UsageText: "ring-signatures board verify 3",
+			ArgsUsage: "<index>",
+			Action:    boardVerify,
+		},
+		{
+			Name:      "head",
+			Usage:     "print the current tree head of the log",
+			UsageText: "ring-signatures board head",
+			Action:    boardHead,
+		},
+		{
+			Name:      "prove-inclusion",
+			Usage:     "prove that a post is included in the log",
+			UsageText: "ring-signatures board prove-inclusion 3 [--size 10] > proof.json",
+			ArgsUsage: "<index>",
+			Action:    boardProveInclusion,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "size",
+					Usage: "size of the log version to prove against (defaults to the current size)",
+				},
+			},
+		},
+		{
+			Name:      "prove-consistency",
+			Usage:     "prove that an older version of the log is a prefix of the current one",
+			UsageText: "ring-signatures board prove-consistency 5 [--size 10] > proof.json",
+			ArgsUsage: "<old-size>",
+			Action:    boardProveConsistency,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "size",
+					Usage: "size of the newer log version (defaults to the current size)",
+				},
+			},
+		},
+		{
+			Name:      "check-inclusion",
+			Usage:     "check an inclusion proof, without access to the board",
+			UsageText: "ring-signatures board check-inclusion --proof proof.json --root 5f3a...",
+			Action:    boardCheckInclusion,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "proof, p",
+					Usage: "file containing the proof",
+				},
+				cli.StringFlag{
+					Name:  "root",
+					Usage: "hex-encoded root hash you trust for that log size",
+				},
+			},
+		},
+		{
+			Name:      "check-consistency",
+			Usage:     "check a consistency proof, without access to the board",
+			UsageText: "ring-signatures board check-consistency --proof proof.json --old-root 5f3a...",
+			Action:    boardCheckConsistency,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "proof, p",
+					Usage: "file containing the proof",
+				},
+				cli.StringFlag{
+					Name:  "old-root",
+					Usage: "hex-encoded root hash you previously saw for the old log size",
+				},
+			},
+		},
+	},
+}
+
+// boardDir returns the board directory given in the command flags.
+func boardDir(c *cli.Context) (string, error) {
+	if dir := c.GlobalString("dir"); len(dir) > 0 {
+		return dir, nil
+	}
+
+	dir := c.GlobalString("config-dir")
+	if len(dir) == 0 {
+		var err error
+		dir, err = store.DefaultDir()
+		if err != nil {
+			return "", cli.NewExitError(err, 1)
+		}
+	}
+
+	return filepath.Join(dir, "board"), nil
+}
+
+// openBoard opens the board given in the command flags.
+func openBoard(c *cli.Context) (*board.Board, error) {
+	dir, err := boardDir(c)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := board.Open(dir)
+	if err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	return b, nil
+}
+
+// intArg parses the first command argument.
+func intArg(c *cli.Context, name string) (int, error) {
+	if !c.Args().Present() {
+		return 0, cli.NewExitError(fmt.Sprintf("you need to specify the %s", name), 1)
+	}
+
+	i, err := strconv.Atoi(c.Args().First())
+	if err != nil || i < 0 {
+		return 0, cli.NewExitError(fmt.Sprintf("invalid %s: %s", name, c.Args().First()), 1)
+	}
+
+	return i, nil
+}
+
+// sizeFlag returns the log size given with --size, where 0 means the
+// current size.
+func sizeFlag(c *cli.Context) (int, error) {
+	size := c.Int("size")
+	if size < 0 {
+		return 0, cli.NewExitError(fmt.Sprintf("invalid size: %d", size), 1)
+	}
+
+	return size, nil
+}
+
+// printJSON prints an indented JSON value.
+func printJSON(v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
+
+// readProof reads a JSON proof from the file given in the command flags,
+// or from stdin.
+func readProof(c *cli.Context, proof interface{}) error {
+	var b []byte
+	var err error
+	if proofFile := c.String("proof"); len(proofFile) > 0 {
+		b, err = ioutil.ReadFile(proofFile)
+	} else {
+		b, err = ioutil.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	err = json.Unmarshal(b, proof)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid proof: %s", err), 1)
+	}
+
+	return nil
+}
+
+// checkRoot checks that a root hash matches the hex-encoded trusted root,
+// if one was provided.
+func checkRoot(trusted string, root []byte) error {
+	if len(trusted) == 0 {
+		return nil
+	}
+
+	if hex.EncodeToString(root) != trusted {
+		return cli.NewExitError("the proof doesn't match the trusted root", 1)
+	}
+
+	return nil
+}
+
+func boardInit(c *cli.Context) error {
+	dir, err := boardDir(c)
+	if err != nil {
+		return err
+	}
+
+	ringKeys, err := ringFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	b, err := board.Create(dir, ringKeys)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Board created in %s for %d members.\n", dir, len(b.Ring()))
+
+	return nil
+}
+
+func boardPost(c *cli.Context) error {
+	message := c.String("message")
+	if len(message) == 0 {
+		return cli.NewExitError("you need to specify the message to post", 1)
+	}
+
+	sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil || len(sk) == 0 {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	b, err := openBoard(c)
+	if err != nil {
+		return err
+	}
+
+	i, err := b.Post(crand.Reader, ring.PrivateKey(sk), message)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	head := b.Head()
+	fmt.Printf("Post: %d\n", i)
+	fmt.Printf("Tree size: %d\n", head.Size)
+	fmt.Printf("Root: %x\n", head.Root)
+
+	return nil
+}
+
+func boardList(c *cli.Context) error {
+	b, err := openBoard(c)
+	if err != nil {
+		return err
+	}
+
+	for i, p := range b.Posts() {
+		status := "ok"
+		if err := p.Verify(b.Ring()); err != nil {
+			status = "INVALID"
+		}
+
+		fmt.Printf("%d [%s] %s\n", i, status, p.Message)
+	}
+
+	return nil
+}
+
+func boardVerify(c *cli.Context) error {
+	index, err := intArg(c, "post index")
+	if err != nil {
+		return err
+	}
+
+	b, err := openBoard(c)
+	if err != nil {
+		return err
+	}
+
+	err = b.Verify(index)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println("Valid post.")
+
+	return nil
+}
+
+func boardHead(c *cli.Context) error {
+	b, err := openBoard(c)
+	if err != nil {
+		return err
+	}
+
+	head := b.Head()
+	fmt.Printf("Tree size: %d\n", head.Size)
+	fmt.Printf("Root: %x\n", head.Root)
+
+	return nil
+}
+
+func boardProveInclusion(c *cli.Context) error {
+	index, err := intArg(c, "post index")
+	if err != nil {
+		return err
+	}
+
+	b, err := openBoard(c)
+	if err != nil {
+		return err
+	}
+
+	size, err := sizeFlag(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := b.ProveInclusion(index, size)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	return printJSON(p)
+}
+
+func boardProveConsistency(c *cli.Context) error {
+	oldSize, err := intArg(c, "old size")
+	if err != nil {
+		return err
+	}
+
+	b, err := openBoard(c)
+	if err != nil {
+		return err
+	}
+
+	size, err := sizeFlag(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := b.ProveConsistency(oldSize, size)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	return printJSON(p)
+}
+
+func boardCheckInclusion(c *cli.Context) error {
+	p := &board.InclusionProof{}
+	err := readProof(c, p)
+	if err != nil {
+		return err
+	}
+
+	err = p.Verify()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	err = checkRoot(c.String("root"), p.Root)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Post %d is included in the log of size %d with root %x.\n", p.Index, p.Size, p.Root)
+
+	return nil
+}
+
+func boardCheckConsistency(c *cli.Context) error {
+	p := &board.ConsistencyProof{}
+	err := readProof(c, p)
+	if err != nil {
+		return err
+	}
+
+	err = p.Verify()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	err = checkRoot(c.String("old-root"), p.OldRoot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The log of size %d with root %x extends the log of size %d with root %x.\n", p.Size, p.Root, p.OldSize, p.OldRoot)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var claimCommand = cli.Command{
+	Name:      "claim",
+	Usage:     "prove that you produced a claimable signature",
+	UsageText: "ring-signatures claim --signature s1GN4tUr3 --private-key Pr1v4T3k3y",
+	Action:    claim,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "signature, s",
+			Usage: "signature to claim",
+		},
+		cli.StringFlag{
+			Name:  "private-key, k",
+			Usage: "private key that produced the signature",
+		},
+	},
+}
+
+var verifyClaimCommand = cli.Command{
+	Name:      "verify-claim",
+	Usage:     "verify that a ring member produced a signature",
+	UsageText: "ring-signatures verify-claim --message \"hello!\" --signature s1GN4tUr3 --claim cl41m",
+	Action:    verifyClaim,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "message, m",
+			Usage: "signed message",
+		},
+		cli.StringFlag{
+			Name:  "signature, s",
+			Usage: "claimed signature",
+		},
+		cli.StringFlag{
+			Name:  "claim",
+			Usage: "claim produced by the signer",
+		},
+	},
+}
+
+// signatureFromFlags decodes the signature given in the command flags.
+func signatureFromFlags(c *cli.Context) (*ring.Signature, error) {
+	sigStr := c.String("signature")
+	if len(sigStr) == 0 {
+		return nil, cli.NewExitError("you need to specify the signature", 1)
+	}
+
+	sig := &ring.Signature{}
+	err := sig.Decode(sigStr)
+	if err != nil {
+		return nil, cli.NewExitError("invalid signature", 1)
+	}
+
+	return sig, nil
+}
+
+func claim(c *cli.Context) error {
+	sig, err := signatureFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil || len(sk) == 0 {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	cl, err := ring.PrivateKey(sk).Claim(sig)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	encoded, err := cl.Encode()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(encoded)
+
+	return nil
+}
+
+func verifyClaim(c *cli.Context) error {
+	sig, err := signatureFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	cl := &ring.Claim{}
+	err = cl.Decode(c.String("claim"))
+	if err != nil {
+		return cli.NewExitError("invalid claim", 1)
+	}
+
+	err = sig.VerifyClaim([]byte(c.String("message")), cl)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println("Claim is valid.")
+	fmt.Printf("Signer: %s\n", ring.ConfigEncodeKey(cl.Signer()))
+	fmt.Printf("Fingerprint: %s\n", fingerprint(cl.Signer()))
+
+	return nil
+}
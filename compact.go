@@ -0,0 +1,128 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+// compactRingFlags are the flags selecting the ring of a compact signature.
+var compactRingFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "ring, r",
+		Usage: "comma-separated list of public keys to use as ring",
+	},
+	cli.StringFlag{
+		Name:  "ring-jwks",
+		Usage: "JSON Web Key Set file containing the public keys to use as ring",
+	},
+	cli.StringFlag{
+		Name:  "ring-name, n",
+		Usage: "name of a saved ring to use (see the ring command)",
+	},
+}
+
+var compactCommand = cli.Command{
+	Name:  "compact",
+	Usage: "sign with large rings using signatures of logarithmic size",
+	Subcommands: []cli.Command{
+		{
+			Name:      "sign",
+			Usage:     "sign a message with a ring",
+			UsageText: "ring-signatures compact sign --message \"hello!\" --private-key Pr1v4T3k3y --ring-name company",
+			Action:    compactSign,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message to sign",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "private key to use for signing",
+				},
+			}, compactRingFlags...),
+		},
+		{
+			Name:  "verify",
+			Usage: "verify a message signature",
+			UsageText: "ring-signatures compact verify --message \"hello!\" --signature s1GN4tUr3 --ring-name company\n" +
+				"   Compact signatures don't contain the ring: you need the ring they were produced with.",
+			Action: compactVerify,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to verify",
+				},
+			}, compactRingFlags...),
+		},
+	},
+}
+
+func compactSign(c *cli.Context) error {
+	ringKeys, err := ringFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	m := c.String("message")
+	if len(m) == 0 {
+		return cli.NewExitError("you need to specify a message to sign", 1)
+	}
+
+	sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil || len(sk) == 0 {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	privKey := ring.PrivateKey(sk)
+	i, err := privKey.SignerIndex(ringKeys)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sig, err := privKey.SignCompact(crand.Reader, []byte(m), ringKeys, i)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sigStr, err := sig.Encode()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(sigStr)
+
+	return nil
+}
+
+func compactVerify(c *cli.Context) error {
+	sigStr := c.String("signature")
+	if len(sigStr) == 0 {
+		return cli.NewExitError("you need to specify the signature to verify", 1)
+	}
+
+	sig := &ring.CompactSignature{}
+	err := sig.Decode(sigStr)
+	if err != nil {
+		return cli.NewExitError("invalid signature", 1)
+	}
+
+	ringKeys, err := ringFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	if !sig.Verify([]byte(c.String("message")), ringKeys) {
+		return cli.NewExitError(ring.ErrInvalidSignature, 1)
+	}
+
+	fmt.Println("Signature is valid.")
+
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package main exports the ring signatures library as a C shared library.
+// Build it with `make cshared`: the C interface is documented in ringsig.h.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	crand "crypto/rand"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+// Return codes, matching ringsig.h.
+const (
+	ringsigOK                 = 0
+	ringsigErrInvalidArgument = 1
+	ringsigErrInvalidKey      = 2
+	ringsigErrSignerNotInRing = 3
+	ringsigErrInvalidSig      = 4
+	ringsigErrInternal        = 5
+)
+
+const (
+	// publicKeySize is the size of an uncompressed P-384 point.
+	publicKeySize = 97
+
+	// privateKeySize is the size of a P-384 scalar.
+	privateKeySize = 48
+
+	// maxRingSize bounds the ring so that buffer sizes don't overflow and
+	// signatures can be decoded by ringsig_verify.
+	maxRingSize = ring.MaxRingSize
+)
+
+func main() {}
+
+// errorCode maps library errors to return codes.
+func errorCode(err error) C.int {
+	switch errors.Cause(err) {
+	case nil:
+		return ringsigOK
+	case ring.ErrEmptyMessage, ring.ErrRingTooSmall, ring.ErrRingTooLarge, ring.ErrInvalidSignerIndex:
+		return ringsigErrInvalidArgument
+	case ring.ErrUnsupportedKeyType, ring.ErrInvalidPublicKey, ring.ErrInvalidPrivateKey, ring.ErrSignerMismatch:
+		return ringsigErrInvalidKey
+	case ring.ErrSignerNotInRing:
+		return ringsigErrSignerNotInRing
+	default:
+		return ringsigErrInternal
+	}
+}
+
+// goBytes copies a C buffer to Go memory.
+func goBytes(p *C.uint8_t, n int) []byte {
+	if n == 0 {
+		return nil
+	}
+
+	return C.GoBytes(unsafe.Pointer(p), C.int(n))
+}
+
+// setBytes copies b to a buffer allocated with malloc and returns it to
+// the caller.
+func setBytes(b []byte, out **C.uint8_t, outLen *C.size_t) {
+	*out = (*C.uint8_t)(C.CBytes(b))
+	*outLen = C.size_t(len(b))
+}
+
+//export ringsig_generate
+func ringsig_generate(publicKey, privateKey *C.uint8_t) C.int {
+	if publicKey == nil || privateKey == nil {
+		return ringsigErrInvalidArgument
+	}
+
+	pk, sk := ring.Generate(crand.Reader)
+	if len(pk) != publicKeySize || len(sk) != privateKeySize {
+		return ringsigErrInternal
+	}
+
+	copy((*[publicKeySize]byte)(unsafe.Pointer(publicKey))[:], pk)
+	copy((*[privateKeySize]byte)(unsafe.Pointer(privateKey))[:], sk)
+
+	return ringsigOK
+}
+
+//export ringsig_sign
+func ringsig_sign(
+	message *C.uint8_t,
+	messageLen C.size_t,
+	privateKey *C.uint8_t,
+	ringKeys *C.uint8_t,
+	ringLen C.size_t,
+	signature **C.uint8_t,
+	signatureLen *C.size_t,
+) C.int {
+	if (message == nil && messageLen > 0) || privateKey == nil || ringKeys == nil || signature == nil || signatureLen == nil {
+		return ringsigErrInvalidArgument
+	}
+
+	if ringLen > maxRingSize || messageLen > C.size_t(1<<31-1) {
+		return ringsigErrInvalidArgument
+	}
+
+	sk := ring.PrivateKey(goBytes(privateKey, privateKeySize))
+	raw := goBytes(ringKeys, int(ringLen)*publicKeySize)
+	keys := make([]ring.PublicKey, int(ringLen))
+	for i := range keys {
+		keys[i] = ring.PublicKey(raw[i*publicKeySize : (i+1)*publicKeySize])
+	}
+
+	sig, err := sk.SignAuto(crand.Reader, goBytes(message, int(messageLen)), keys)
+	if err != nil {
+		return errorCode(err)
+	}
+
+	b, err := sig.Marshal()
+	if err != nil {
+		return ringsigErrInternal
+	}
+
+	setBytes(b, signature, signatureLen)
+
+	return ringsigOK
+}
+
+//export ringsig_verify
+func ringsig_verify(message *C.uint8_t, messageLen C.size_t, signature *C.uint8_t, signatureLen C.size_t) C.int {
+	if (message == nil && messageLen > 0) || signature == nil {
+		return ringsigErrInvalidArgument
+	}
+
+	if messageLen > C.size_t(1<<31-1) || signatureLen > C.size_t(1<<31-1) {
+		return ringsigErrInvalidArgument
+	}
+
+	sig := &ring.Signature{}
+	if err := sig.Unmarshal(goBytes(signature, int(signatureLen))); err != nil {
+		return ringsigErrInvalidSig
+	}
+
+	if !sig.Verify(goBytes(message, int(messageLen))) {
+		return ringsigErrInvalidSig
+	}
+
+	return ringsigOK
+}
+
+//export ringsig_encode
+func ringsig_encode(signature *C.uint8_t, signatureLen C.size_t, encoded **C.char) C.int {
+	if signature == nil || encoded == nil || signatureLen > C.size_t(1<<31-1) {
+		return ringsigErrInvalidArgument
+	}
+
+	sig := &ring.Signature{}
+	if err := sig.Unmarshal(goBytes(signature, int(signatureLen))); err != nil {
+		return ringsigErrInvalidSig
+	}
+
+	s, err := sig.Encode()
+	if err != nil {
+		return ringsigErrInternal
+	}
+
+	*encoded = C.CString(s)
+
+	return ringsigOK
+}
+
+//export ringsig_decode
+func ringsig_decode(encoded *C.char, signature **C.uint8_t, signatureLen *C.size_t) C.int {
+	if encoded == nil || signature == nil || signatureLen == nil {
+		return ringsigErrInvalidArgument
+	}
+
+	sig := &ring.Signature{}
+	if err := sig.Decode(C.GoString(encoded)); err != nil {
+		return ringsigErrInvalidSig
+	}
+
+	b, err := sig.Marshal()
+	if err != nil {
+		return ringsigErrInternal
+	}
+
+	setBytes(b, signature, signatureLen)
+
+	return ringsigOK
+}
+
+//export ringsig_free
+func ringsig_free(buffer unsafe.Pointer) {
+	C.free(buffer)
+}
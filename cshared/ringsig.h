@@ -0,0 +1,82 @@
+/*
+ * ringsig.h - C interface to the ring signatures library.
+ *
+ * Build the shared library with `make cshared` and link with -lringsig.
+ *
+ * Keys are raw P-384 keys: public keys are uncompressed curve points and
+ * private keys are big-endian scalars. A ring is a contiguous array of
+ * public keys. Signatures are opaque byte buffers; ringsig_encode and
+ * ringsig_decode convert them to and from the base64 string format used by
+ * the command line tool and the Go library.
+ *
+ * Buffers returned by the library are allocated with malloc and must be
+ * released with ringsig_free.
+ */
+
+#ifndef RINGSIG_H
+#define RINGSIG_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#define RINGSIG_PUBLIC_KEY_SIZE 97
+#define RINGSIG_PRIVATE_KEY_SIZE 48
+
+/* Return codes. */
+#define RINGSIG_OK 0
+#define RINGSIG_ERR_INVALID_ARGUMENT 1
+#define RINGSIG_ERR_INVALID_KEY 2
+#define RINGSIG_ERR_SIGNER_NOT_IN_RING 3
+#define RINGSIG_ERR_INVALID_SIGNATURE 4
+#define RINGSIG_ERR_INTERNAL 5
+
+/*
+ * Generates a key pair.
+ * public_key must hold RINGSIG_PUBLIC_KEY_SIZE bytes and private_key
+ * RINGSIG_PRIVATE_KEY_SIZE bytes.
+ */
+int ringsig_generate(uint8_t *public_key, uint8_t *private_key);
+
+/*
+ * Signs a message with a ring of ring_len public keys.
+ * The signer's public key must be in the ring, which can't contain more
+ * than 1024 keys.
+ * On success, *signature is set to a buffer of *signature_len bytes.
+ */
+int ringsig_sign(const uint8_t *message, size_t message_len,
+                 const uint8_t *private_key,
+                 const uint8_t *ring, size_t ring_len,
+                 uint8_t **signature, size_t *signature_len);
+
+/*
+ * Verifies a message signature.
+ * Returns RINGSIG_OK if the signature is valid and
+ * RINGSIG_ERR_INVALID_SIGNATURE otherwise.
+ */
+int ringsig_verify(const uint8_t *message, size_t message_len,
+                   const uint8_t *signature, size_t signature_len);
+
+/*
+ * Encodes a signature to a NUL-terminated base64 string.
+ */
+int ringsig_encode(const uint8_t *signature, size_t signature_len, char **encoded);
+
+/*
+ * Decodes a signature from its NUL-terminated base64 string.
+ */
+int ringsig_decode(const char *encoded, uint8_t **signature, size_t *signature_len);
+
+/*
+ * Releases a buffer returned by the library.
+ */
+void ringsig_free(void *buffer);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* RINGSIG_H */
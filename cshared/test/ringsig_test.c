@@ -0,0 +1,86 @@
+/*
+ * Tests the C interface of the ring signatures library.
+ * Run it with `make ctest`.
+ */
+
+#include <stdio.h>
+#include <string.h>
+
+#include "ringsig.h"
+
+#define RING_SIZE 3
+
+static int failures = 0;
+
+#define CHECK(cond, msg)                                  \
+	do {                                                  \
+		if (!(cond)) {                                    \
+			fprintf(stderr, "FAIL: %s (%s:%d)\n", msg,    \
+			        __FILE__, __LINE__);                  \
+			failures++;                                   \
+		} else {                                          \
+			printf("ok: %s\n", msg);                      \
+		}                                                 \
+	} while (0)
+
+int main(void) {
+	uint8_t ring[RING_SIZE * RINGSIG_PUBLIC_KEY_SIZE];
+	uint8_t private_keys[RING_SIZE][RINGSIG_PRIVATE_KEY_SIZE];
+	const uint8_t message[] = "hello from C";
+	const uint8_t other[] = "goodbye from C";
+	uint8_t *signature = NULL;
+	size_t signature_len = 0;
+	uint8_t *decoded = NULL;
+	size_t decoded_len = 0;
+	char *encoded = NULL;
+	int i, rc;
+
+	for (i = 0; i < RING_SIZE; i++) {
+		rc = ringsig_generate(ring + i * RINGSIG_PUBLIC_KEY_SIZE, private_keys[i]);
+		CHECK(rc == RINGSIG_OK, "generate key pair");
+	}
+
+	rc = ringsig_sign(message, sizeof(message) - 1, private_keys[1],
+	                  ring, RING_SIZE, &signature, &signature_len);
+	CHECK(rc == RINGSIG_OK && signature_len > 0, "sign message");
+
+	rc = ringsig_verify(message, sizeof(message) - 1, signature, signature_len);
+	CHECK(rc == RINGSIG_OK, "verify signature");
+
+	rc = ringsig_verify(other, sizeof(other) - 1, signature, signature_len);
+	CHECK(rc == RINGSIG_ERR_INVALID_SIGNATURE, "reject signature of another message");
+
+	rc = ringsig_encode(signature, signature_len, &encoded);
+	CHECK(rc == RINGSIG_OK && strlen(encoded) > 0, "encode signature");
+
+	rc = ringsig_decode(encoded, &decoded, &decoded_len);
+	CHECK(rc == RINGSIG_OK, "decode signature");
+
+	rc = ringsig_verify(message, sizeof(message) - 1, decoded, decoded_len);
+	CHECK(rc == RINGSIG_OK, "verify decoded signature");
+
+	rc = ringsig_decode("not a signature", &decoded, &decoded_len);
+	CHECK(rc == RINGSIG_ERR_INVALID_SIGNATURE, "reject invalid encoding");
+
+	ringsig_free(signature);
+	signature = NULL;
+
+	rc = ringsig_sign(message, sizeof(message) - 1, private_keys[2],
+	                  ring, RING_SIZE - 1, &signature, &signature_len);
+	CHECK(rc == RINGSIG_ERR_SIGNER_NOT_IN_RING, "reject signer outside of the ring");
+
+	rc = ringsig_sign(message, 0, private_keys[0],
+	                  ring, RING_SIZE, &signature, &signature_len);
+	CHECK(rc == RINGSIG_ERR_INVALID_ARGUMENT, "reject empty message");
+
+	ringsig_free(encoded);
+	ringsig_free(decoded);
+
+	if (failures > 0) {
+		fprintf(stderr, "%d check(s) failed\n", failures);
+		return 1;
+	}
+
+	printf("all checks passed\n");
+	return 0;
+}
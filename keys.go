@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	crand "crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var keyCommand = cli.Command{
+	Name:  "key",
+	Usage: "manage keys derived from a mnemonic phrase",
+	Subcommands: []cli.Command{
+		{
+			Name:  "derive",
+			Usage: "derive a key from a mnemonic phrase",
+			UsageText: "ring-signatures key derive --path \"m/1'\"\n" +
+				"   The mnemonic phrase is read from standard input if it isn't provided with --mnemonic.",
+			Action: keyDerive,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "path, p",
+					Usage: "derivation path of the key",
+					Value: ring.DefaultDerivationPath,
+				},
+				cli.StringFlag{
+					Name:  "mnemonic",
+					Usage: "mnemonic phrase to derive the key from",
+				},
+				cli.StringFlag{
+					Name:  "passphrase",
+					Usage: "optional passphrase protecting the mnemonic",
+				},
+			},
+		},
+	},
+}
+
+func generateMnemonic(c *cli.Context) error {
+	fmt.Println("Generating your mnemonic phrase...")
+	mnemonic, err := ring.NewMnemonic(crand.Reader)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Mnemonic: %s\n", mnemonic)
+	fmt.Println("Write it down and keep it safe: it can restore all the keys derived from it.")
+
+	return printDerivedKey(mnemonic, c.String("passphrase"), ring.DefaultDerivationPath)
+}
+
+func keyDerive(c *cli.Context) error {
+	mnemonic := c.String("mnemonic")
+	if len(mnemonic) == 0 {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && len(line) == 0 {
+			return cli.NewExitError("you need to specify the mnemonic phrase", 1)
+		}
+
+		mnemonic = strings.TrimSpace(line)
+	}
+
+	return printDerivedKey(mnemonic, c.String("passphrase"), c.String("path"))
+}
+
+// printDerivedKey derives the key at the given path and prints it.
+func printDerivedKey(mnemonic, passphrase, path string) error {
+	seed, err := ring.MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	master, err := ring.NewMasterKey(seed)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	k, err := master.Derive(path)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Path: %s\n", path)
+	fmt.Printf("Public key: %s\n", ring.ConfigEncodeKey(k.PublicKey()))
+	fmt.Printf("Fingerprint: %s\n", fingerprint(k.PublicKey()))
+	fmt.Printf("Private key: %s\n", ring.ConfigEncodeKey(k.PrivateKey))
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var latticeCommand = cli.Command{
+	Name:  "lattice",
+	Usage: "sign with experimental post-quantum lattice keys",
+	Subcommands: []cli.Command{
+		{
+			Name:      "generate",
+			Usage:     "generate a lattice public and private key",
+			UsageText: "ring-signatures lattice generate",
+			Action:    schemeGenerate(ring.Lattice),
+		},
+		{
+			Name:  "sign",
+			Usage: "sign a message with a ring of lattice keys",
+			UsageText: "ring-signatures lattice sign --message \"hello!\" --private-key Pr1v4T3k3y" +
+				" --ring 4l1c3 --ring b0b",
+			Action: schemeSign(ring.Lattice),
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message to sign",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "lattice private key to use for signing",
+				},
+				cli.StringSliceFlag{
+					Name:  "ring, r",
+					Usage: "lattice public key of the ring",
+				},
+			},
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify a message signature",
+			UsageText: "ring-signatures lattice verify --message \"hello!\" --signature-file sig.txt",
+			Action:    schemeVerify(ring.Lattice),
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to verify",
+				},
+				cli.StringFlag{
+					Name:  "signature-file",
+					Usage: "file containing the signature to verify (lattice signatures are large)",
+				},
+			},
+		},
+	},
+}
+
+// schemeGenerate generates a key pair of the given scheme.
+func schemeGenerate(scheme ring.Scheme) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		fmt.Printf("Generating your %s public and private key...\n", scheme.Name())
+		pk, sk := scheme.Generate(crand.Reader)
+
+		fmt.Printf("Public key: %s\n", ring.ConfigEncodeKey(pk))
+		fmt.Printf("Private key: %s\n", ring.ConfigEncodeKey(sk))
+		fmt.Println("You can (should) share your public key with the world, but make sure you secure your private key.")
+
+		return nil
+	}
+}
+
+// schemeSign signs a message with a ring of keys of the given scheme.
+func schemeSign(scheme ring.Scheme) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		m := c.String("message")
+		if len(m) == 0 {
+			return cli.NewExitError("you need to specify a message to sign", 1)
+		}
+
+		if len(c.String("private-key")) == 0 {
+			return cli.NewExitError("you need to specify the private key to use for signing", 1)
+		}
+
+		sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+		if err != nil {
+			return cli.NewExitError("invalid private key", 1)
+		}
+
+		var ringKeys [][]byte
+		for _, k := range c.StringSlice("ring") {
+			b, err := ring.ConfigDecodeKey(k)
+			if err != nil {
+				return cli.NewExitError(fmt.Sprintf("invalid public key: %s", k), 1)
+			}
+
+			ringKeys = append(ringKeys, b)
+		}
+
+		sig, err := scheme.Sign(crand.Reader, []byte(m), ringKeys, sk)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		sigStr, err := sig.Encode()
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		fmt.Println(sigStr)
+
+		return nil
+	}
+}
+
+// schemeVerify verifies a signature of the given scheme.
+func schemeVerify(scheme ring.Scheme) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		sigStr := c.String("signature")
+		if file := c.String("signature-file"); len(file) > 0 {
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+
+			sigStr = strings.TrimSpace(string(b))
+		}
+
+		if len(sigStr) == 0 {
+			return cli.NewExitError("you need to specify the signature to verify", 1)
+		}
+
+		sig, err := scheme.Decode(sigStr)
+		if err != nil {
+			return cli.NewExitError("invalid signature", 1)
+		}
+
+		if !sig.Verify([]byte(c.String("message"))) {
+			return cli.NewExitError(ring.ErrInvalidSignature, 1)
+		}
+
+		fmt.Println("Signature is valid.")
+		fmt.Printf("Ring members (%d):\n", len(sig.Members()))
+		for i, pk := range sig.Members() {
+			fmt.Printf("%d: %s key %s\n", i, scheme.Name(), keyPrefix(pk))
+		}
+
+		return nil
+	}
+}
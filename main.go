@@ -4,8 +4,12 @@ package main
 import (
 	crand "crypto/rand"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/t-bast/ring-signatures/ring"
 	"github.com/urfave/cli"
 )
@@ -17,13 +21,31 @@ func main() {
 	app.Usage = "generate and verify ring signatures."
 	app.Version = "0.1.0"
 
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:   "config-dir",
+			Usage:  "directory where named rings are stored",
+			EnvVar: "RING_SIGNATURES_HOME",
+		},
+	}
+
 	app.Commands = []cli.Command{
 		{
 			Name:      "generate",
 			Aliases:   []string{"g"},
 			Usage:     "generate a public and private key",
-			UsageText: "ring-signatures generate",
+			UsageText: "ring-signatures generate [--mnemonic]",
 			Action:    generate,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "mnemonic",
+					Usage: "generate a mnemonic phrase to back up your keys and derive the first key from it",
+				},
+				cli.StringFlag{
+					Name:  "passphrase",
+					Usage: "optional passphrase protecting the mnemonic",
+				},
+			},
 		},
 		{
 			Name:    "sign",
@@ -33,8 +55,10 @@ func main() {
 				"   She wants to use Bob and Carol's public keys to form a ring.\n" +
 				"   Bob's public key is \"b0b\" and Carol's public key is \"c4r0l\".\n" +
 				"   Alice can form the ring [c4r0l, 4l1c3, b0b] and hide herself in that ring with the following command:\n" +
-				"   ring-signatures sign --message \"hello!\" --private-key 4l1c3" +
-				" --ring-index 1 --ring c4r0l --ring 4l1c3 --ring b0b",
+				"   ring-signatures sign --message \"hello!\" --private-key Pr1v4T3k3y" +
+				" --ring c4r0l --ring 4l1c3 --ring b0b\n" +
+				"   If she saved that ring as \"team\", she can simply use:\n" +
+				"   ring-signatures sign --message \"hello!\" --private-key Pr1v4T3k3y --ring-name team",
 			Action: sign,
 			Flags: []cli.Flag{
 				cli.StringFlag{
@@ -47,11 +71,39 @@ func main() {
 				},
 				cli.IntFlag{
 					Name:  "ring-index, i",
-					Usage: "index of your private key in the signing ring",
+					Usage: "index of your public key in the signing ring (found automatically when omitted)",
 				},
 				cli.StringSliceFlag{
 					Name:  "ring, r",
-					Usage: "comma-separated list of public keys to use as ring",
+					Usage: "comma-separated list of public keys (or fingerprint prefixes of saved ring members) to use as ring",
+				},
+				cli.StringFlag{
+					Name:  "ring-jwks",
+					Usage: "JSON Web Key Set file containing the public keys to use as ring",
+				},
+				cli.StringFlag{
+					Name:  "ring-name, n",
+					Usage: "name of a saved ring to use (see the ring command)",
+				},
+				cli.BoolFlag{
+					Name:  "canonical",
+					Usage: "sort the ring and remove duplicates to hide your position in the ring",
+				},
+				cli.StringFlag{
+					Name:  "context",
+					Usage: "application context to bind to the signature",
+				},
+				cli.DurationFlag{
+					Name:  "expires-in",
+					Usage: "validity duration of the signature (e.g. 24h)",
+				},
+				cli.StringSliceFlag{
+					Name:  "claim",
+					Usage: "key=value claim to bind to the signature",
+				},
+				cli.BoolFlag{
+					Name:  "claimable",
+					Usage: "let you prove later that you produced the signature (see the claim command)",
 				},
 			},
 		},
@@ -70,18 +122,96 @@ func main() {
 					Name:  "signature, s",
 					Usage: "signature to verify",
 				},
+				cli.StringFlag{
+					Name:  "ring-name, n",
+					Usage: "name of a saved ring the signature must have been produced with",
+				},
+				cli.StringFlag{
+					Name:  "subset-of",
+					Usage: "name of a saved ring all the signature's ring members must belong to",
+				},
+				cli.BoolFlag{
+					Name:  "canonical",
+					Usage: "require the ring to be in canonical order",
+				},
+				cli.BoolFlag{
+					Name:  "strict",
+					Usage: "only accept the canonical encoding of the signature",
+				},
+				cli.StringFlag{
+					Name:  "context",
+					Usage: "application context the signature must have been produced for",
+				},
+				cli.DurationFlag{
+					Name:  "max-age",
+					Usage: "maximum age of the signature (e.g. 1h)",
+				},
+				cli.StringSliceFlag{
+					Name:  "claim",
+					Usage: "key=value claim the signature must contain",
+				},
+				cli.StringFlag{
+					Name: "revocations",
+					Usage: "revocation list file: reject signatures with keys revoked when they were received" +
+						" (lists older than the last one accepted from the issuer are rejected)",
+				},
+				cli.StringFlag{
+					Name:  "revocation-issuer",
+					Usage: "public key of the revocation list issuer",
+				},
+				cli.StringFlag{
+					Name:  "received-at",
+					Usage: "RFC 3339 time the signature was received, checked against revocations (defaults to now)",
+				},
+				cli.BoolFlag{
+					Name:  "signed-time",
+					Usage: "check revocations at the signature's timestamp instead, which the signer can backdate",
+				},
 			},
 		},
+		{
+			Name:      "inspect",
+			Aliases:   []string{"i"},
+			Usage:     "show the ring members of a signature",
+			UsageText: "ring-signatures inspect --signature s1GN4tUr3",
+			Action:    inspect,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to inspect",
+				},
+			},
+		},
+		ringCommand,
+		keyCommand,
+		voteCommand,
+		boardCommand,
+		accountableCommand,
+		claimCommand,
+		verifyClaimCommand,
+		compactCommand,
+		rsaCommand,
+		mixedCommand,
+		latticeCommand,
+		vectorsCommand,
+		benchCommand,
+		serveCommand,
+		revocationCommand,
 	}
 
 	app.Run(os.Args)
 }
 
 func generate(c *cli.Context) error {
+	if c.Bool("mnemonic") {
+		return generateMnemonic(c)
+	}
+
 	fmt.Println("Generating your public and private key...")
 	pk, sk := ring.Generate(crand.Reader)
 
 	fmt.Printf("Public key: %s\n", ring.ConfigEncodeKey(pk))
+	fmt.Printf("Fingerprint: %s\n", fingerprint(pk))
 	fmt.Printf("Private key: %s\n", ring.ConfigEncodeKey(sk))
 	fmt.Println("You can (should) share your public key with the world, but make sure you secure your private key.")
 
@@ -89,19 +219,9 @@ func generate(c *cli.Context) error {
 }
 
 func sign(c *cli.Context) error {
-	r := c.StringSlice("ring")
-	if len(r) == 0 {
-		return cli.NewExitError("you need to specify a ring to use for signing", 1)
-	}
-
-	var ringKeys []ring.PublicKey
-	for _, key := range r {
-		pkBytes, err := ring.ConfigDecodeKey(key)
-		if err != nil {
-			return cli.NewExitError(fmt.Sprintf("invalid public key: %s", key), 1)
-		}
-
-		ringKeys = append(ringKeys, ring.PublicKey(pkBytes))
+	ringKeys, err := ringFromFlags(c)
+	if err != nil {
+		return err
 	}
 
 	m := c.String("message")
@@ -109,11 +229,6 @@ func sign(c *cli.Context) error {
 		return cli.NewExitError("you need to specify a message to sign", 1)
 	}
 
-	i := c.Int("ring-index")
-	if i < 0 {
-		return cli.NewExitError("invalid index", 1)
-	}
-
 	pk := c.String("private-key")
 	if len(pk) == 0 {
 		return cli.NewExitError("you need to specify the private key to use for signing", 1)
@@ -126,8 +241,72 @@ func sign(c *cli.Context) error {
 
 	privKey := ring.PrivateKey(privKeyBytes)
 
+	if c.Bool("canonical") {
+		if c.IsSet("ring-index") {
+			return cli.NewExitError("the ring index can't be used with a canonical ring", 1)
+		}
+
+		ringKeys = ring.CanonicalRing(ringKeys)
+	}
+
+	i := c.Int("ring-index")
+	if !c.IsSet("ring-index") {
+		i, err = privKey.SignerIndex(ringKeys)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	if i < 0 {
+		return cli.NewExitError("invalid index", 1)
+	}
+
+	claims, err := claimsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	hasAttributes := len(c.String("context")) > 0 || c.IsSet("expires-in") || len(claims) > 0
+	if hasAttributes && c.Bool("claimable") {
+		return cli.NewExitError("claimable signatures can't have signed attributes", 1)
+	}
+
+	// Keys given directly are checked against the periods stored in all
+	// saved rings, so that expired keys are caught without --ring-name.
+	var descriptors []ring.KeyDescriptor
+	if name := c.String("ring-name"); len(name) > 0 {
+		descriptors, err = storedDescriptors(c, name)
+		if err != nil {
+			return err
+		}
+	} else {
+		descriptors = knownDescriptors(c)
+	}
+
+	if err := ring.CheckRingValidity(ringKeys, descriptors, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+
 	fmt.Println("Signing message...")
-	sig, err := privKey.Sign(crand.Reader, []byte(m), ringKeys, i)
+	var sig *ring.Signature
+	if c.Bool("claimable") {
+		sig, err = privKey.SignClaimable(crand.Reader, []byte(m), ringKeys, i)
+	} else if hasAttributes {
+		attrs := ring.Attributes{
+			Timestamp: time.Now(),
+			Context:   c.String("context"),
+			Claims:    claims,
+		}
+
+		if c.IsSet("expires-in") {
+			attrs.Expiry = attrs.Timestamp.Add(c.Duration("expires-in"))
+		}
+
+		sig, err = privKey.SignWithAttributes(crand.Reader, []byte(m), ringKeys, i, attrs)
+	} else {
+		sig, err = privKey.Sign(crand.Reader, []byte(m), ringKeys, i)
+	}
+
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -142,6 +321,83 @@ func sign(c *cli.Context) error {
 	return nil
 }
 
+// ringFromFlags reads the ring of public keys from the command flags.
+func ringFromFlags(c *cli.Context) ([]ring.PublicKey, error) {
+	if name := c.String("ring-name"); len(name) > 0 {
+		s, err := openStore(c)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := s.Load(name)
+		if err != nil {
+			return nil, cli.NewExitError(err, 1)
+		}
+
+		return r.Keys, nil
+	}
+
+	if jwksFile := c.String("ring-jwks"); len(jwksFile) > 0 {
+		b, err := ioutil.ReadFile(jwksFile)
+		if err != nil {
+			return nil, cli.NewExitError(err, 1)
+		}
+
+		ringKeys, err := ring.RingFromJWKS(b)
+		if err != nil {
+			return nil, cli.NewExitError(err, 1)
+		}
+
+		return ringKeys, nil
+	}
+
+	r := c.StringSlice("ring")
+	if len(r) == 0 {
+		return nil, cli.NewExitError("you need to specify a ring to use for signing", 1)
+	}
+
+	return decodePublicKeys(c, r)
+}
+
+// claimsFromFlags parses the key=value claims given in the command flags.
+func claimsFromFlags(c *cli.Context) (map[string]string, error) {
+	var claims map[string]string
+	for _, claim := range c.StringSlice("claim") {
+		kv := strings.SplitN(claim, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, cli.NewExitError(fmt.Sprintf("invalid claim: %s", claim), 1)
+		}
+
+		if claims == nil {
+			claims = make(map[string]string)
+		}
+
+		claims[kv[0]] = kv[1]
+	}
+
+	return claims, nil
+}
+
+// fingerprint formats the fingerprint of a public key.
+func fingerprint(pk ring.PublicKey) string {
+	f, err := pk.Fingerprint()
+	if err != nil {
+		return "invalid key"
+	}
+
+	return f.String()
+}
+
+// keyPrefix formats the first bytes of a key for display.
+// Keys shorter than the prefix are printed in full.
+func keyPrefix(b []byte) string {
+	if len(b) > 8 {
+		b = b[:8]
+	}
+
+	return fmt.Sprintf("%x...", b)
+}
+
 func verify(c *cli.Context) error {
 	sigStr := c.String("signature")
 	if len(sigStr) == 0 {
@@ -153,18 +409,151 @@ func verify(c *cli.Context) error {
 		return cli.NewExitError("you need to specify the signed message", 1)
 	}
 
+	opts := ring.DefaultDecodeOptions
+	opts.StrictEncoding = c.Bool("strict")
+
+	sig := &ring.Signature{}
+	err := sig.DecodeWithOptions(sigStr, opts)
+	if errors.Cause(err) == ring.ErrNonCanonicalSignature {
+		return cli.NewExitError(err, 1)
+	} else if err != nil {
+		return cli.NewExitError("invalid signature", 1)
+	}
+
+	claims, err := claimsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	err = sig.VerifyWithPolicy([]byte(m), ring.Policy{
+		Context: c.String("context"),
+		Claims:  claims,
+		MaxAge:  c.Duration("max-age"),
+	})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if path := c.String("revocations"); len(path) > 0 {
+		issuer, err := ring.ConfigDecodeKey(c.String("revocation-issuer"))
+		if err != nil || len(issuer) == 0 {
+			return cli.NewExitError("you need to specify the revocation list issuer", 1)
+		}
+
+		rl, err := loadRevocationList(c, path, issuer)
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("signed-time") {
+			err = sig.VerifyWithRevocations([]byte(m), rl)
+		} else {
+			at := time.Now()
+			if s := c.String("received-at"); len(s) > 0 {
+				at, err = time.Parse(time.RFC3339, s)
+				if err != nil {
+					return cli.NewExitError("invalid received time: use the RFC 3339 format", 1)
+				}
+			}
+
+			err = rl.Check(sig.Ring(), at)
+		}
+
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	for _, name := range []string{c.String("ring-name"), c.String("subset-of")} {
+		if len(name) == 0 {
+			continue
+		}
+
+		descriptors, err := storedDescriptors(c, name)
+		if err != nil {
+			return err
+		}
+
+		if err := sig.VerifyWithDescriptors([]byte(m), descriptors); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	if c.Bool("canonical") && !sig.IsCanonical() {
+		return cli.NewExitError("the ring is not in canonical order", 1)
+	}
+
+	if len(c.String("ring-name")) > 0 {
+		expected, err := ringFromFlags(c)
+		if err != nil {
+			return err
+		}
+
+		if !sig.VerifyWithRing([]byte(m), expected) {
+			return cli.NewExitError("signature was not produced with the expected ring", 1)
+		}
+	}
+
+	if name := c.String("subset-of"); len(name) > 0 {
+		s, err := openStore(c)
+		if err != nil {
+			return err
+		}
+
+		allowed, err := s.Load(name)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		if !sig.VerifySubsetOf([]byte(m), ring.NewKeySet(allowed.Keys...)) {
+			return cli.NewExitError(fmt.Sprintf("some ring members are not in %s", name), 1)
+		}
+	}
+
+	fmt.Println("Signature is valid.")
+
+	return nil
+}
+
+func inspect(c *cli.Context) error {
+	sigStr := c.String("signature")
+	if len(sigStr) == 0 {
+		return cli.NewExitError("you need to specify the signature to inspect", 1)
+	}
+
 	sig := &ring.Signature{}
 	err := sig.Decode(sigStr)
 	if err != nil {
 		return cli.NewExitError("invalid signature", 1)
 	}
 
-	valid := sig.Verify([]byte(m))
-	if !valid {
-		return cli.NewExitError("invalid signature", 1)
+	fmt.Printf("Ring members (%d):\n", len(sig.Ring()))
+	for i, pk := range sig.Ring() {
+		fmt.Printf("%d: %s\n", i, fingerprint(pk))
 	}
 
-	fmt.Println("Signature is valid.")
+	if attrs := sig.Attributes(); attrs != nil {
+		fmt.Println("Signed attributes:")
+		if !attrs.Timestamp.IsZero() {
+			fmt.Printf("   Created: %s\n", attrs.Timestamp.Format(time.RFC3339))
+		}
+
+		if !attrs.Expiry.IsZero() {
+			fmt.Printf("   Expires: %s\n", attrs.Expiry.Format(time.RFC3339))
+		}
+
+		if len(attrs.Context) > 0 {
+			fmt.Printf("   Context: %s\n", attrs.Context)
+		}
+
+		for k, v := range attrs.Claims {
+			fmt.Printf("   %s=%s\n", k, v)
+		}
+	}
+
+	if sig.IsClaimable() {
+		fmt.Println("The signer can claim this signature.")
+	}
 
 	return nil
 }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var mixedCommand = cli.Command{
+	Name:  "mixed",
+	Usage: "sign with a ring mixing EC (P-256, P-384) and RSA keys",
+	Subcommands: []cli.Command{
+		{
+			Name:  "sign",
+			Usage: "sign a message with a ring of mixed keys",
+			UsageText: "ring-signatures mixed sign --message \"hello!\" --private-key-file alice.pem" +
+				" --ring-file alice.pub.pem --ring-file bob.pub.pem",
+			Action: mixedSign,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message to sign",
+				},
+				cli.StringFlag{
+					Name:  "private-key-file, k",
+					Usage: "PEM file containing your EC or RSA private key",
+				},
+				cli.StringSliceFlag{
+					Name:  "ring-file, r",
+					Usage: "PEM file containing an EC or RSA public key of the ring",
+				},
+			},
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify a message signature",
+			UsageText: "ring-signatures mixed verify --message \"hello!\" --signature s1GN4tUr3",
+			Action:    mixedVerify,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to verify",
+				},
+			},
+		},
+	},
+}
+
+// readMixedPublicKey reads a PKIX or PKCS #1 public key from a PEM file.
+func readMixedPublicKey(file string) (crypto.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pk, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return pk, nil
+	}
+
+	pk, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, cli.NewExitError(fmt.Sprintf("invalid public key in %s", file), 1)
+	}
+
+	return pk, nil
+}
+
+// readMixedPrivateKey reads a PKCS #1, SEC 1 or PKCS #8 private key from a
+// PEM file.
+func readMixedPrivateKey(file string) (crypto.PrivateKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if sk, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return sk, nil
+	}
+
+	if sk, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return sk, nil
+	}
+
+	sk, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, cli.NewExitError(fmt.Sprintf("invalid private key in %s", file), 1)
+	}
+
+	return sk, nil
+}
+
+// sameMixedKey returns true if the private key matches the public key.
+func sameMixedKey(sk crypto.PrivateKey, pk crypto.PublicKey) bool {
+	switch sk := sk.(type) {
+	case *ecdsa.PrivateKey:
+		ecKey, ok := pk.(*ecdsa.PublicKey)
+		return ok && ecKey.Curve == sk.Curve && ecKey.X.Cmp(sk.X) == 0 && ecKey.Y.Cmp(sk.Y) == 0
+	case *rsa.PrivateKey:
+		rsaKey, ok := pk.(*rsa.PublicKey)
+		return ok && rsaKey.E == sk.E && rsaKey.N.Cmp(sk.N) == 0
+	default:
+		return false
+	}
+}
+
+func mixedSign(c *cli.Context) error {
+	m := c.String("message")
+	if len(m) == 0 {
+		return cli.NewExitError("you need to specify a message to sign", 1)
+	}
+
+	if len(c.String("private-key-file")) == 0 {
+		return cli.NewExitError("you need to specify the private key to use for signing", 1)
+	}
+
+	sk, err := readMixedPrivateKey(c.String("private-key-file"))
+	if err != nil {
+		return err
+	}
+
+	signerIndex := -1
+	var ringKeys []crypto.PublicKey
+	for _, file := range c.StringSlice("ring-file") {
+		pk, err := readMixedPublicKey(file)
+		if err != nil {
+			return err
+		}
+
+		if sameMixedKey(sk, pk) {
+			signerIndex = len(ringKeys)
+		}
+
+		ringKeys = append(ringKeys, pk)
+	}
+
+	if signerIndex < 0 {
+		return cli.NewExitError(ring.ErrSignerNotInRing, 1)
+	}
+
+	sig, err := ring.SignMixed(crand.Reader, []byte(m), ringKeys, signerIndex, sk)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sigStr, err := sig.Encode()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(sigStr)
+
+	return nil
+}
+
+func mixedVerify(c *cli.Context) error {
+	sigStr := c.String("signature")
+	if len(sigStr) == 0 {
+		return cli.NewExitError("you need to specify the signature to verify", 1)
+	}
+
+	sig := &ring.MixedSignature{}
+	err := sig.Decode(sigStr)
+	if err != nil {
+		return cli.NewExitError("invalid signature", 1)
+	}
+
+	if !sig.Verify([]byte(c.String("message"))) {
+		return cli.NewExitError(ring.ErrInvalidSignature, 1)
+	}
+
+	fmt.Println("Signature is valid.")
+	fmt.Printf("Ring members (%d):\n", len(sig.Ring()))
+	for i, pk := range sig.Ring() {
+		switch pk := pk.(type) {
+		case *ecdsa.PublicKey:
+			x := pk.X.FillBytes(make([]byte, (pk.Curve.Params().BitSize+7)/8))
+			fmt.Printf("%d: %s key %s\n", i, pk.Curve.Params().Name, keyPrefix(x))
+		case *rsa.PublicKey:
+			fmt.Printf("%d: %d-bit RSA key %s\n", i, pk.N.BitLen(), keyPrefix(pk.N.Bytes()))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var revocationCommand = cli.Command{
+	Name:  "revocation",
+	Usage: "manage signed lists of revoked ring member keys",
+	Subcommands: []cli.Command{
+		{
+			Name:  "revoke",
+			Usage: "add a key to a revocation list and sign it",
+			UsageText: "ring-signatures revocation revoke --list revoked.json --private-key 1ssu3r" +
+				" --key b0b --effective 2018-09-27T00:00:00Z --reason \"laptop stolen\"",
+			Action: revocationRevoke,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "list, l",
+					Usage: "revocation list file, created if it doesn't exist",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "private key of the list issuer",
+				},
+				cli.StringFlag{
+					Name:  "key",
+					Usage: "public key to revoke",
+				},
+				cli.StringFlag{
+					Name:  "fingerprint, f",
+					Usage: "fingerprint of the key to revoke",
+				},
+				cli.StringFlag{
+					Name:  "effective",
+					Usage: "RFC 3339 time from which the key is revoked (defaults to now)",
+				},
+				cli.StringFlag{
+					Name:  "reason",
+					Usage: "reason for the revocation",
+				},
+			},
+		},
+		{
+			Name:      "show",
+			Usage:     "verify and show a revocation list",
+			UsageText: "ring-signatures revocation show --list revoked.json --issuer 1ssu3r",
+			Action:    revocationShow,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "list, l",
+					Usage: "revocation list file",
+				},
+				cli.StringFlag{
+					Name:  "issuer",
+					Usage: "public key of the list issuer",
+				},
+			},
+		},
+	},
+}
+
+// loadRevocationList reads a revocation list and verifies it was signed
+// by the issuer.
+// The list is recorded in the store, and lists older than the last one
+// accepted from the same issuer are rejected.
+func loadRevocationList(c *cli.Context, path string, issuer ring.PublicKey) (*ring.RevocationList, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	rl := &ring.RevocationList{}
+	if err := rl.Unmarshal(b); err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	if err := rl.Verify(issuer); err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	s, err := openStore(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.AcceptRevocationList(rl); err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	return rl, nil
+}
+
+func revocationRevoke(c *cli.Context) error {
+	path := c.String("list")
+	if len(path) == 0 {
+		return cli.NewExitError("you need to specify the revocation list file", 1)
+	}
+
+	if len(c.String("private-key")) == 0 {
+		return cli.NewExitError("you need to specify the issuer's private key", 1)
+	}
+
+	skBytes, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	sk := ring.PrivateKey(skBytes)
+
+	var f ring.Fingerprint
+	switch {
+	case len(c.String("key")) > 0:
+		pk, err := ring.ConfigDecodeKey(c.String("key"))
+		if err != nil {
+			return cli.NewExitError("invalid public key", 1)
+		}
+
+		f, err = ring.PublicKey(pk).Fingerprint()
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	case len(c.String("fingerprint")) > 0:
+		f, err = ring.ParseFingerprint(c.String("fingerprint"))
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	default:
+		return cli.NewExitError("you need to specify the key to revoke", 1)
+	}
+
+	effective := time.Now()
+	if s := c.String("effective"); len(s) > 0 {
+		effective, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return cli.NewExitError("invalid effective time: use the RFC 3339 format", 1)
+		}
+	}
+
+	rl := &ring.RevocationList{}
+	if _, err := os.Stat(path); err == nil {
+		rl, err = loadRevocationList(c, path, sk.Public())
+		if err != nil {
+			return err
+		}
+	}
+
+	rl.Revoke(f, effective, c.String("reason"))
+	rl.Issued = time.Time{}
+	if err := rl.Sign(crand.Reader, sk); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	b, err := rl.Marshal()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Revoked %s from %s.\n", f, effective.UTC().Format(time.RFC3339))
+
+	return nil
+}
+
+func revocationShow(c *cli.Context) error {
+	if len(c.String("list")) == 0 {
+		return cli.NewExitError("you need to specify the revocation list file", 1)
+	}
+
+	issuer, err := ring.ConfigDecodeKey(c.String("issuer"))
+	if err != nil || len(issuer) == 0 {
+		return cli.NewExitError("you need to specify the issuer's public key", 1)
+	}
+
+	rl, err := loadRevocationList(c, c.String("list"), issuer)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sequence: %d\n", rl.Sequence)
+	fmt.Printf("Issued: %s\n", rl.Issued.Format(time.RFC3339))
+	fmt.Printf("Revoked keys (%d):\n", len(rl.Revocations))
+	for _, r := range rl.Revocations {
+		fmt.Printf("%s from %s", r.Fingerprint, r.Effective.Format(time.RFC3339))
+		if len(r.Reason) > 0 {
+			fmt.Printf(" (%s)", r.Reason)
+		}
+
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,557 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotOpener is returned when opening a signature with a key that
+// isn't the signature's opening authority.
+var ErrNotOpener = errors.New("the key is not the opening authority of the signature")
+
+const (
+	// accountableDomain separates accountable signature hashes from other hashes.
+	accountableDomain = "ring-signatures/accountable/v1"
+
+	// openingDomain separates opening proof hashes from other hashes.
+	openingDomain = "ring-signatures/opening/v1"
+)
+
+// AccountableSignature is a ring signature whose signer can be revealed by
+// a designated opening authority.
+// The signer encrypts their public key to the opener and proves, inside
+// the ring signature, that the ciphertext contains the key of the ring
+// member who signed. Everyone else only learns that a ring member signed.
+type AccountableSignature struct {
+	ring   []PublicKey
+	opener PublicKey
+	c1     []byte
+	c2     []byte
+	c      []byte
+	sx     [][]byte
+	st     [][]byte
+}
+
+// Accountable signing algorithm:
+//	* Let (P(0),...,P(R-1)) be all the public keys in the ring
+//	* Let r be the index of the actual signer and x(r) its private key
+//	* Let Q be the opener's public key
+//	* Randomly choose t in [1:N-1] and encrypt P(r) (ElGamal):
+//		C1 = t*G, C2 = P(r) + t*Q
+//	* Randomly choose k and u in [1:N-1]
+//	* Compute c(r+1 % R) = H(L || m || k*G || u*G || u*Q)
+//	* for i := r+1 % R; i != r; i++%R:
+//		* Randomly choose sx(i) and st(i) in [1:N-1]
+//		* Compute c(i+1 % R) = H(L || m ||
+//			sx(i)*G + c(i)*P(i) ||
+//			st(i)*G + c(i)*C1 ||
+//			st(i)*Q + c(i)*(C2 - P(i)))
+//	* Compute sx(r) = k - c(r)*x(r) mod N and st(r) = u - c(r)*t mod N
+//	* Output signature: (P(0),...,P(R-1),Q,C1,C2,c(0),sx(0),...,st(R-1))
+//
+// where L contains the ring, the opener's key and the ciphertext.
+// The ring can only be closed at an index i where the signer knows x(i)
+// and C2 - P(i) = t*Q, so the ciphertext encrypts the signer's key.
+
+// SignAccountable creates a ring signature for the given message that
+// the opener can later open to reveal the signer.
+func (sk PrivateKey) SignAccountable(
+	rand io.Reader,
+	message []byte,
+	ringKeys []PublicKey,
+	signerIndex int,
+	opener PublicKey,
+) (*AccountableSignature, error) {
+	if len(message) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if signerIndex < 0 || len(ringKeys) <= signerIndex {
+		return nil, ErrInvalidSignerIndex
+	}
+
+	if len(ringKeys) < 2 {
+		return nil, ErrRingTooSmall
+	}
+
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if !bytes.Equal(sk.Public(), ringKeys[signerIndex]) {
+		return nil, ErrSignerMismatch
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+	r := len(ringKeys)
+
+	qx, qy := elliptic.Unmarshal(curve, opener)
+	if qx == nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	// Encrypt the signer's public key.
+
+	t, err := randomParam(curve, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	signerX, signerY := elliptic.Unmarshal(curve, ringKeys[signerIndex])
+	c1x, c1y := curve.ScalarBaseMult(t)
+	tqx, tqy := curve.ScalarMult(qx, qy, t)
+	c2x, c2y := curve.Add(tqx, tqy, signerX, signerY)
+	c1 := elliptic.Marshal(curve, c1x, c1y)
+	c2 := elliptic.Marshal(curve, c2x, c2y)
+
+	prefix := accountablePrefix(ringKeys, opener, c1, c2, message)
+	cs := make([][]byte, r)
+	sxs := make([][]byte, r)
+	sts := make([][]byte, r)
+
+	// Initialize the ring.
+
+	k, err := randomParam(curve, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := randomParam(curve, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	ax, ay := curve.ScalarBaseMult(k)
+	bx, by := curve.ScalarBaseMult(u)
+	dx, dy := curve.ScalarMult(qx, qy, u)
+	cs[(signerIndex+1)%r] = accountableChallenge(curve, prefix, ax, ay, bx, by, dx, dy)
+
+	// Iterate over the whole ring.
+
+	for i := (signerIndex + 1) % r; i != signerIndex; i = (i + 1) % r {
+		sx, err := randomParam(curve, rand)
+		if err != nil {
+			return nil, err
+		}
+
+		st, err := randomParam(curve, rand)
+		if err != nil {
+			return nil, err
+		}
+
+		sxs[i] = sx
+		sts[i] = st
+
+		px, py := elliptic.Unmarshal(curve, ringKeys[i])
+		if px == nil {
+			return nil, ErrInvalidPublicKey
+		}
+
+		cs[(i+1)%r] = accountableStep(curve, prefix, qx, qy, c1x, c1y, c2x, c2y, px, py, cs[i], sx, st)
+	}
+
+	// Close the ring.
+
+	cr := new(big.Int).SetBytes(cs[signerIndex])
+
+	valX := new(big.Int).Mul(cr, new(big.Int).SetBytes(sk))
+	valX.Sub(new(big.Int).SetBytes(k), valX)
+	valX.Mod(valX, n)
+
+	valT := new(big.Int).Mul(cr, new(big.Int).SetBytes(t))
+	valT.Sub(new(big.Int).SetBytes(u), valT)
+	valT.Mod(valT, n)
+
+	if valX.Sign() == 0 || valT.Sign() == 0 {
+		return nil, errors.New("could not produce ring signature")
+	}
+
+	sxs[signerIndex] = valX.Bytes()
+	sts[signerIndex] = valT.Bytes()
+
+	return &AccountableSignature{
+		ring:   ringKeys,
+		opener: opener,
+		c1:     c1,
+		c2:     c2,
+		c:      cs[0],
+		sx:     sxs,
+		st:     sts,
+	}, nil
+}
+
+// Verify verifies the validity of the message signature, including the
+// proof that the opener can reveal the signer.
+// It does not detail why the signature validation failed.
+func (sig *AccountableSignature) Verify(message []byte) bool {
+	if sig == nil || len(sig.ring) < 2 || len(sig.c) == 0 ||
+		len(sig.sx) != len(sig.ring) || len(sig.st) != len(sig.ring) {
+		return false
+	}
+
+	curve := elliptic.P384()
+	qx, qy := elliptic.Unmarshal(curve, sig.opener)
+	c1x, c1y := elliptic.Unmarshal(curve, sig.c1)
+	c2x, c2y := elliptic.Unmarshal(curve, sig.c2)
+	if qx == nil || c1x == nil || c2x == nil {
+		return false
+	}
+
+	prefix := accountablePrefix(sig.ring, sig.opener, sig.c1, sig.c2, message)
+
+	c := sig.c
+	for i := 0; i < len(sig.ring); i++ {
+		px, py := elliptic.Unmarshal(curve, sig.ring[i])
+		if px == nil {
+			return false
+		}
+
+		c = accountableStep(curve, prefix, qx, qy, c1x, c1y, c2x, c2y, px, py, c, sig.sx[i], sig.st[i])
+	}
+
+	return bytes.Equal(c, sig.c)
+}
+
+// Ring returns the public keys of the ring that produced the signature.
+func (sig *AccountableSignature) Ring() []PublicKey {
+	return sig.ring
+}
+
+// Opener returns the public key of the authority that can open the signature.
+func (sig *AccountableSignature) Opener() PublicKey {
+	return sig.opener
+}
+
+// accountableStep computes the challenge following c(i).
+func accountableStep(
+	curve elliptic.Curve,
+	prefix []byte,
+	qx, qy, c1x, c1y, c2x, c2y, px, py *big.Int,
+	c, sx, st []byte,
+) []byte {
+	// C2 - P(i)
+	ex, ey := curve.Add(c2x, c2y, px, new(big.Int).Sub(curve.Params().P, py))
+
+	ax, ay := addMult(curve, nil, nil, sx, px, py, c)
+	bx, by := addMult(curve, nil, nil, st, c1x, c1y, c)
+	dx, dy := addMult(curve, qx, qy, st, ex, ey, c)
+	return accountableChallenge(curve, prefix, ax, ay, bx, by, dx, dy)
+}
+
+// accountablePrefix hashes the parts of the challenge that don't change
+// along the ring.
+func accountablePrefix(ringKeys []PublicKey, opener, c1, c2, message []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(accountableDomain))
+	for _, pk := range ringKeys {
+		writeLengthPrefixed(h, pk)
+	}
+
+	writeLengthPrefixed(h, opener)
+	writeLengthPrefixed(h, c1)
+	writeLengthPrefixed(h, c2)
+	writeLengthPrefixed(h, message)
+	return h.Sum(nil)
+}
+
+// accountableChallenge computes the next challenge of the ring.
+func accountableChallenge(curve elliptic.Curve, prefix []byte, ax, ay, bx, by, dx, dy *big.Int) []byte {
+	b := append([]byte{}, prefix...)
+	b = append(b, elliptic.Marshal(curve, ax, ay)...)
+	b = append(b, elliptic.Marshal(curve, bx, by)...)
+	b = append(b, elliptic.Marshal(curve, dx, dy)...)
+	return hash(b)
+}
+
+// Opening reveals the signer of an accountable signature.
+// It contains a proof that the opener decrypted the signature correctly,
+// so that the opener can't accuse another ring member.
+type Opening struct {
+	signer PublicKey
+	c      []byte
+	s      []byte
+}
+
+// Opening proof (Chaum-Pedersen), with x the opener's private key:
+//	* Compute the decryption D = C2 - P = x*C1
+//	* Randomly choose k in [1:N-1]
+//	* Compute c = H(Q || C1 || C2 || P || k*G || k*C1)
+//	* Compute s = k - c*x mod N
+//	* Verifiers check that c = H(Q || C1 || C2 || P || s*G + c*Q || s*C1 + c*D)
+
+// Open reveals the signer of an accountable signature.
+// The private key must be the signature's opener key. The signature
+// should be verified first: an invalid signature may not be opened.
+func (sk PrivateKey) Open(rand io.Reader, sig *AccountableSignature) (*Opening, error) {
+	if !bytes.Equal(sk.Public(), sig.opener) {
+		return nil, ErrNotOpener
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+
+	c1x, c1y := elliptic.Unmarshal(curve, sig.c1)
+	c2x, c2y := elliptic.Unmarshal(curve, sig.c2)
+	if c1x == nil || c2x == nil {
+		return nil, ErrInvalidSignature
+	}
+
+	// P = C2 - x*C1
+	dx, dy := curve.ScalarMult(c1x, c1y, sk)
+	px, py := curve.Add(c2x, c2y, dx, new(big.Int).Sub(curve.Params().P, dy))
+	signer := PublicKey(elliptic.Marshal(curve, px, py))
+
+	found := false
+	for _, pk := range sig.ring {
+		if bytes.Equal(pk, signer) {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, ErrSignerNotInRing
+	}
+
+	k, err := randomParam(curve, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	ax, ay := curve.ScalarBaseMult(k)
+	bx, by := curve.ScalarMult(c1x, c1y, k)
+	c := openingChallenge(curve, sig, signer, ax, ay, bx, by)
+
+	s := new(big.Int).Mul(new(big.Int).SetBytes(c), new(big.Int).SetBytes(sk))
+	s.Sub(new(big.Int).SetBytes(k), s)
+	s.Mod(s, n)
+
+	return &Opening{signer: signer, c: c, s: s.Bytes()}, nil
+}
+
+// Signer returns the public key of the revealed signer.
+func (o *Opening) Signer() PublicKey {
+	return o.signer
+}
+
+// Verify verifies the signature of the message, and that the opening was
+// produced by the signature's opener and reveals a ring member as the
+// actual signer.
+func (o *Opening) Verify(sig *AccountableSignature, message []byte) bool {
+	if o == nil || sig == nil || len(o.c) == 0 {
+		return false
+	}
+
+	if !sig.Verify(message) || !NewKeySet(sig.ring...).Contains(o.signer) {
+		return false
+	}
+
+	curve := elliptic.P384()
+	qx, qy := elliptic.Unmarshal(curve, sig.opener)
+	c1x, c1y := elliptic.Unmarshal(curve, sig.c1)
+	c2x, c2y := elliptic.Unmarshal(curve, sig.c2)
+	px, py := elliptic.Unmarshal(curve, o.signer)
+	if qx == nil || c1x == nil || c2x == nil || px == nil {
+		return false
+	}
+
+	// D = C2 - P
+	dx, dy := curve.Add(c2x, c2y, px, new(big.Int).Sub(curve.Params().P, py))
+
+	ax, ay := addMult(curve, nil, nil, o.s, qx, qy, o.c)
+	bx, by := addMult(curve, c1x, c1y, o.s, dx, dy, o.c)
+	return bytes.Equal(o.c, openingChallenge(curve, sig, o.signer, ax, ay, bx, by))
+}
+
+// openingChallenge computes the challenge of an opening proof.
+func openingChallenge(curve elliptic.Curve, sig *AccountableSignature, signer PublicKey, ax, ay, bx, by *big.Int) []byte {
+	h := sha256.New()
+	h.Write([]byte(openingDomain))
+	writeLengthPrefixed(h, sig.opener)
+	writeLengthPrefixed(h, sig.c1)
+	writeLengthPrefixed(h, sig.c2)
+	writeLengthPrefixed(h, signer)
+	h.Write(elliptic.Marshal(curve, ax, ay))
+	h.Write(elliptic.Marshal(curve, bx, by))
+	return h.Sum(nil)
+}
+
+// Marshal marshals an accountable signature to a byte representation.
+func (sig *AccountableSignature) Marshal() ([]byte, error) {
+	return json.Marshal(struct {
+		R  []PublicKey
+		O  []byte
+		C1 []byte
+		C2 []byte
+		C  []byte
+		SX [][]byte
+		ST [][]byte
+	}{
+		R:  sig.ring,
+		O:  sig.opener,
+		C1: sig.c1,
+		C2: sig.c2,
+		C:  sig.c,
+		SX: sig.sx,
+		ST: sig.st,
+	})
+}
+
+// Unmarshal unmarshals an accountable signature from its byte representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
+func (sig *AccountableSignature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, DefaultDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals an accountable signature from its byte
+// representation and checks it against the given limits.
+func (sig *AccountableSignature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
+	unmarshalled := struct {
+		R  []PublicKey
+		O  []byte
+		C1 []byte
+		C2 []byte
+		C  []byte
+		SX [][]byte
+		ST [][]byte
+	}{}
+	err := opts.unmarshalJSON(data, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkRing(unmarshalled.R, unmarshalled.SX); err != nil {
+		return err
+	}
+
+	if err := opts.checkResponses(len(unmarshalled.R), unmarshalled.ST); err != nil {
+		return err
+	}
+
+	if err := opts.checkHash("challenge", unmarshalled.C); err != nil {
+		return err
+	}
+
+	for _, p := range []struct {
+		name  string
+		point []byte
+	}{{"opener key", unmarshalled.O}, {"ciphertext", unmarshalled.C1}, {"ciphertext", unmarshalled.C2}} {
+		if err := opts.checkPoint(p.name, p.point); err != nil {
+			return err
+		}
+	}
+
+	sig.ring = unmarshalled.R
+	sig.opener = unmarshalled.O
+	sig.c1 = unmarshalled.C1
+	sig.c2 = unmarshalled.C2
+	sig.c = unmarshalled.C
+	sig.sx = unmarshalled.SX
+	sig.st = unmarshalled.ST
+
+	return opts.checkEncoding(data, sig.Marshal)
+}
+
+// Encode encodes an accountable signature to a friendly string representation.
+func (sig *AccountableSignature) Encode() (string, error) {
+	b, err := sig.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes an accountable signature from its friendly string representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
+func (sig *AccountableSignature) Decode(data string) error {
+	return sig.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes an accountable signature from its friendly
+// string representation and checks it against the given limits.
+func (sig *AccountableSignature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
+}
+
+// Encode encodes an opening to a friendly string representation.
+func (o *Opening) Encode() (string, error) {
+	b, err := json.Marshal(struct {
+		P []byte
+		C []byte
+		S []byte
+	}{
+		P: o.signer,
+		C: o.c,
+		S: o.s,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes an opening from its friendly string representation.
+// It rejects openings exceeding the limits of DefaultDecodeOptions.
+func (o *Opening) Decode(data string) error {
+	return o.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes an opening from its friendly string representation
+// and checks it against the given limits.
+func (o *Opening) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	unmarshalled := struct {
+		P []byte
+		C []byte
+		S []byte
+	}{}
+	err = opts.unmarshalJSON(b, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkProof(unmarshalled.P, unmarshalled.C, unmarshalled.S); err != nil {
+		return err
+	}
+
+	o.signer = unmarshalled.P
+	o.c = unmarshalled.C
+	o.s = unmarshalled.S
+
+	return nil
+}
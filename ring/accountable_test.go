@@ -0,0 +1,120 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountable(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, bobPriv := Generate(nil)
+	carolPub, _ := Generate(nil)
+	openerPub, openerPriv := Generate(nil)
+	_, otherPriv := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub, carolPub}
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := alicePriv.SignAccountable(nil, nil, ringKeys, 0, openerPub)
+		assert.EqualError(t, err, ErrEmptyMessage.Error())
+
+		_, err = alicePriv.SignAccountable(nil, []byte("report"), ringKeys[:1], 0, openerPub)
+		assert.EqualError(t, err, ErrRingTooSmall.Error())
+
+		_, err = alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 1, openerPub)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+
+		_, err = alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 0, PublicKey("opener"))
+		assert.EqualError(t, err, ErrInvalidPublicKey.Error())
+	})
+
+	t.Run("Signs, verifies and opens", func(t *testing.T) {
+		for i, signer := range []PrivateKey{alicePriv, bobPriv} {
+			sig, err := signer.SignAccountable(nil, []byte("report"), ringKeys, i, openerPub)
+			assert.NoError(t, err, "SignAccountable()")
+			assert.True(t, sig.Verify([]byte("report")))
+			assert.False(t, sig.Verify([]byte("another report")))
+			assert.Equal(t, openerPub, sig.Opener())
+
+			_, err = otherPriv.Open(nil, sig)
+			assert.EqualError(t, err, ErrNotOpener.Error())
+
+			opening, err := openerPriv.Open(nil, sig)
+			assert.NoError(t, err, "Open()")
+			assert.Equal(t, ringKeys[i], opening.Signer())
+			assert.True(t, opening.Verify(sig, []byte("report")))
+		}
+	})
+
+	t.Run("Rejects forged openings", func(t *testing.T) {
+		sig, err := alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 0, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+
+		opening, err := openerPriv.Open(nil, sig)
+		assert.NoError(t, err, "Open()")
+
+		// The opener can't accuse another ring member.
+		forged := *opening
+		forged.signer = bobPub
+		assert.False(t, forged.Verify(sig, []byte("report")))
+
+		// The opening only applies to the opened signature.
+		other, err := alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 0, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+		assert.False(t, opening.Verify(other, []byte("report")))
+	})
+
+	t.Run("Rejects openings of invalid signatures", func(t *testing.T) {
+		sig, err := alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 0, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+
+		opening, err := openerPriv.Open(nil, sig)
+		assert.NoError(t, err, "Open()")
+		assert.False(t, opening.Verify(sig, []byte("another report")))
+
+		// The opening proof only covers the ciphertext: the rest of the
+		// signature can be tampered with without invalidating it.
+		sig.sx[1] = sig.sx[0]
+		assert.False(t, sig.Verify([]byte("report")))
+		assert.False(t, opening.Verify(sig, []byte("report")))
+	})
+
+	t.Run("Rejects a ciphertext that doesn't match the signer", func(t *testing.T) {
+		sig, err := alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 0, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+
+		bobSig, err := bobPriv.SignAccountable(nil, []byte("report"), ringKeys, 1, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+
+		sig.c1, sig.c2 = bobSig.c1, bobSig.c2
+		assert.False(t, sig.Verify([]byte("report")))
+
+		sig, err = alicePriv.SignAccountable(nil, []byte("report"), ringKeys, 0, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+		sig.opener = alicePub
+		assert.False(t, sig.Verify([]byte("report")))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := bobPriv.SignAccountable(nil, []byte("report"), ringKeys, 1, openerPub)
+		assert.NoError(t, err, "SignAccountable()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &AccountableSignature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify([]byte("report")))
+
+		opening, err := openerPriv.Open(nil, decoded)
+		assert.NoError(t, err, "Open()")
+
+		encoded, err = opening.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decodedOpening := &Opening{}
+		assert.NoError(t, decodedOpening.Decode(encoded), "Decode()")
+		assert.Equal(t, bobPub, decodedOpening.Signer())
+		assert.True(t, decodedOpening.Verify(sig, []byte("report")))
+	})
+}
@@ -0,0 +1,230 @@
+package ring
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature is cryptographically invalid.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrMissingAttributes is returned when a policy requires signed
+	// attributes but the signature has none.
+	ErrMissingAttributes = errors.New("the signature has no signed attributes")
+
+	// ErrContextMismatch is returned when the signed context doesn't
+	// match the expected one.
+	ErrContextMismatch = errors.New("the signature was produced for another context")
+
+	// ErrSignatureExpired is returned when a signature has expired.
+	ErrSignatureExpired = errors.New("the signature has expired")
+
+	// ErrSignatureFromFuture is returned when a signature's timestamp
+	// is in the future.
+	ErrSignatureFromFuture = errors.New("the signature timestamp is in the future")
+
+	// ErrSignatureTooOld is returned when a signature is older than
+	// the policy allows.
+	ErrSignatureTooOld = errors.New("the signature is too old")
+
+	// ErrClaimMismatch is returned when a required claim is missing
+	// or has an unexpected value.
+	ErrClaimMismatch = errors.New("a required claim is missing or invalid")
+)
+
+// attributesDomain separates attributed messages from raw messages.
+const attributesDomain = "ring-signatures/attributes/v1"
+
+// Attributes is signed metadata attached to a signature.
+// Times are stored with a one-second precision.
+type Attributes struct {
+	// Timestamp is the creation time of the signature.
+	Timestamp time.Time
+	// Context identifies the application the signature is meant for.
+	Context string
+	// Expiry is the time after which the signature should be rejected.
+	// A zero value means the signature doesn't expire.
+	Expiry time.Time
+	// Claims are arbitrary key/value pairs.
+	Claims map[string]string
+}
+
+// normalize returns a copy of the attributes with times truncated to the
+// second, so that they survive encoding.
+func (attrs *Attributes) normalize() *Attributes {
+	if attrs == nil {
+		return nil
+	}
+
+	normalized := &Attributes{
+		Timestamp: unixTime(timestampOf(attrs.Timestamp)),
+		Context:   attrs.Context,
+		Expiry:    unixTime(timestampOf(attrs.Expiry)),
+	}
+
+	if len(attrs.Claims) > 0 {
+		normalized.Claims = make(map[string]string, len(attrs.Claims))
+		for k, v := range attrs.Claims {
+			normalized.Claims[k] = v
+		}
+	}
+
+	return normalized
+}
+
+// unixTime converts a unix timestamp to a time, keeping the zero time
+// for a zero timestamp.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0).UTC()
+}
+
+// bind binds the attributes to the message.
+// Without attributes, the message is left unchanged so that signatures
+// without attributes are compatible with older versions.
+func (attrs *Attributes) bind(message []byte) []byte {
+	if attrs == nil {
+		return message
+	}
+
+	var b []byte
+	b = appendBytes(b, []byte(attributesDomain))
+	b = appendUint64(b, uint64(timestampOf(attrs.Timestamp)))
+	b = appendUint64(b, uint64(timestampOf(attrs.Expiry)))
+	b = appendBytes(b, []byte(attrs.Context))
+
+	keys := make([]string, 0, len(attrs.Claims))
+	for k := range attrs.Claims {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	b = appendUint64(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = appendBytes(b, []byte(k))
+		b = appendBytes(b, []byte(attrs.Claims[k]))
+	}
+
+	return appendBytes(b, message)
+}
+
+// timestampOf returns the unix timestamp of a time, or 0 for the zero time.
+func timestampOf(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// appendUint64 appends a big-endian integer.
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// appendBytes appends length-prefixed bytes.
+func appendBytes(b []byte, v []byte) []byte {
+	b = appendUint64(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// SignWithAttributes creates a ring signature for the given message and
+// binds the given attributes to it.
+// If no timestamp is provided, the current time is used.
+func (sk PrivateKey) SignWithAttributes(
+	rand io.Reader,
+	message []byte,
+	ringKeys []PublicKey,
+	signerIndex int,
+	attrs Attributes,
+) (*Signature, error) {
+	if attrs.Timestamp.IsZero() {
+		attrs.Timestamp = time.Now()
+	}
+
+	return sk.sign(rand, message, ringKeys, signerIndex, attrs.normalize(), nil)
+}
+
+// Attributes returns the signed attributes of the signature, or nil
+// if the signature has none.
+func (sig *Signature) Attributes() *Attributes {
+	return sig.attrs.normalize()
+}
+
+// Policy defines the checks a verifier applies to signed attributes.
+type Policy struct {
+	// RequireAttributes rejects signatures without signed attributes.
+	RequireAttributes bool
+	// Context, if set, must match the signed context.
+	Context string
+	// Claims must all be present with the same value.
+	Claims map[string]string
+	// MaxAge, if set, rejects signatures created longer ago.
+	MaxAge time.Duration
+	// ClockSkew is the tolerance applied to time checks.
+	ClockSkew time.Duration
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// VerifyWithPolicy verifies the validity of the message signature and
+// checks its signed attributes against the policy.
+// Unlike Verify, it explains why the verification failed.
+func (sig *Signature) VerifyWithPolicy(message []byte, policy Policy) error {
+	if !sig.Verify(message) {
+		return ErrInvalidSignature
+	}
+
+	return policy.Check(sig.attrs)
+}
+
+// Check checks signed attributes against the policy.
+func (policy Policy) Check(attrs *Attributes) error {
+	if attrs == nil {
+		if policy.RequireAttributes || len(policy.Context) > 0 || len(policy.Claims) > 0 || policy.MaxAge > 0 {
+			return ErrMissingAttributes
+		}
+
+		return nil
+	}
+
+	now := time.Now()
+	if policy.Now != nil {
+		now = policy.Now()
+	}
+
+	if len(policy.Context) > 0 && attrs.Context != policy.Context {
+		return ErrContextMismatch
+	}
+
+	if !attrs.Expiry.IsZero() && now.After(attrs.Expiry.Add(policy.ClockSkew)) {
+		return ErrSignatureExpired
+	}
+
+	if !attrs.Timestamp.IsZero() && attrs.Timestamp.After(now.Add(policy.ClockSkew)) {
+		return ErrSignatureFromFuture
+	}
+
+	if policy.MaxAge > 0 && (attrs.Timestamp.IsZero() || now.Sub(attrs.Timestamp) > policy.MaxAge+policy.ClockSkew) {
+		return ErrSignatureTooOld
+	}
+
+	for k, v := range policy.Claims {
+		if signed, ok := attrs.Claims[k]; !ok || signed != v {
+			return errors.Wrap(ErrClaimMismatch, k)
+		}
+	}
+
+	return nil
+}
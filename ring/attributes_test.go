@@ -0,0 +1,100 @@
+package ring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub}
+
+	now := time.Unix(1538000000, 0).UTC()
+	clock := func() time.Time { return now }
+
+	message := []byte("signed with context")
+	attrs := Attributes{
+		Timestamp: now,
+		Context:   "votes",
+		Expiry:    now.Add(time.Hour),
+		Claims:    map[string]string{"round": "3"},
+	}
+
+	sig, err := alicePriv.SignWithAttributes(nil, message, ringKeys, 0, attrs)
+	assert.NoError(t, err, "SignWithAttributes()")
+
+	t.Run("Binds attributes to the signature", func(t *testing.T) {
+		assert.True(t, sig.Verify(message))
+		assert.Equal(t, &attrs, sig.Attributes())
+
+		for _, tampered := range []*Attributes{
+			{Timestamp: now.Add(time.Second), Context: "votes", Expiry: attrs.Expiry, Claims: attrs.Claims},
+			{Timestamp: now, Context: "polls", Expiry: attrs.Expiry, Claims: attrs.Claims},
+			{Timestamp: now, Context: "votes", Claims: attrs.Claims},
+			{Timestamp: now, Context: "votes", Expiry: attrs.Expiry, Claims: map[string]string{"round": "4"}},
+			nil,
+		} {
+			forged := *sig
+			forged.attrs = tampered
+			assert.False(t, forged.Verify(message))
+		}
+	})
+
+	t.Run("Does not change signatures without attributes", func(t *testing.T) {
+		plain, err := alicePriv.Sign(nil, message, ringKeys, 0)
+		assert.NoError(t, err, "Sign()")
+		assert.Nil(t, plain.Attributes())
+		assert.NoError(t, plain.VerifyWithPolicy(message, Policy{}))
+		assert.Equal(t, ErrMissingAttributes, plain.VerifyWithPolicy(message, Policy{Context: "votes"}))
+	})
+
+	t.Run("Fills missing timestamp", func(t *testing.T) {
+		sig, err := alicePriv.SignWithAttributes(nil, message, ringKeys, 0, Attributes{Context: "votes"})
+		assert.NoError(t, err, "SignWithAttributes()")
+		assert.WithinDuration(t, time.Now(), sig.Attributes().Timestamp, time.Minute)
+	})
+
+	t.Run("Marshals attributes", func(t *testing.T) {
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &Signature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.Equal(t, sig.Attributes(), decoded.Attributes())
+		assert.True(t, decoded.Verify(message))
+	})
+
+	t.Run("Checks policy", func(t *testing.T) {
+		assert.NoError(t, sig.VerifyWithPolicy(message, Policy{
+			RequireAttributes: true,
+			Context:           "votes",
+			Claims:            map[string]string{"round": "3"},
+			MaxAge:            time.Minute,
+			Now:               clock,
+		}))
+
+		assert.Equal(t, ErrInvalidSignature, sig.VerifyWithPolicy([]byte("other"), Policy{Now: clock}))
+		assert.Equal(t, ErrContextMismatch, sig.VerifyWithPolicy(message, Policy{Context: "polls", Now: clock}))
+
+		err := sig.VerifyWithPolicy(message, Policy{Claims: map[string]string{"round": "4"}, Now: clock})
+		assert.Equal(t, ErrClaimMismatch, errors.Cause(err))
+
+		err = sig.VerifyWithPolicy(message, Policy{Claims: map[string]string{"poll": "3"}, Now: clock})
+		assert.Equal(t, ErrClaimMismatch, errors.Cause(err))
+	})
+
+	t.Run("Checks times", func(t *testing.T) {
+		at := func(d time.Duration) func() time.Time {
+			return func() time.Time { return now.Add(d) }
+		}
+
+		assert.Equal(t, ErrSignatureExpired, sig.VerifyWithPolicy(message, Policy{Now: at(2 * time.Hour)}))
+		assert.NoError(t, sig.VerifyWithPolicy(message, Policy{Now: at(2 * time.Hour), ClockSkew: 2 * time.Hour}))
+		assert.Equal(t, ErrSignatureFromFuture, sig.VerifyWithPolicy(message, Policy{Now: at(-time.Minute)}))
+		assert.Equal(t, ErrSignatureTooOld, sig.VerifyWithPolicy(message, Policy{Now: at(10 * time.Minute), MaxAge: time.Minute}))
+	})
+}
@@ -0,0 +1,57 @@
+package ring
+
+import (
+	"bytes"
+	"io"
+	"sort"
+)
+
+// CanonicalRing returns the canonical ordering of a ring: keys are sorted
+// by their encoding and duplicates are removed.
+// Using the canonical ordering prevents the position of the signer in the
+// ring from leaking information (e.g. if a tool always puts the signer first).
+func CanonicalRing(ringKeys []PublicKey) []PublicKey {
+	sorted := make([]PublicKey, len(ringKeys))
+	copy(sorted, ringKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	canonical := sorted[:0]
+	for i, k := range sorted {
+		if i == 0 || !bytes.Equal(k, sorted[i-1]) {
+			canonical = append(canonical, k)
+		}
+	}
+
+	return canonical
+}
+
+// IsCanonicalRing returns true if the ring is in canonical order.
+func IsCanonicalRing(ringKeys []PublicKey) bool {
+	for i := 1; i < len(ringKeys); i++ {
+		if bytes.Compare(ringKeys[i-1], ringKeys[i]) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SignCanonical creates a ring signature for the given message after
+// putting the ring in canonical order.
+// The position of the signer is found automatically.
+func (sk PrivateKey) SignCanonical(rand io.Reader, message []byte, ringKeys []PublicKey) (*Signature, error) {
+	return sk.SignAuto(rand, message, CanonicalRing(ringKeys))
+}
+
+// IsCanonical returns true if the signature's ring is in canonical order.
+func (sig *Signature) IsCanonical() bool {
+	return sig != nil && IsCanonicalRing(sig.ring)
+}
+
+// VerifyCanonical verifies the validity of the message signature and
+// requires the ring to be in canonical order.
+func (sig *Signature) VerifyCanonical(message []byte) bool {
+	return sig.IsCanonical() && sig.Verify(message)
+}
@@ -0,0 +1,55 @@
+package ring
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalRing(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+	carolPub, _ := Generate(nil)
+
+	t.Run("Sorts and removes duplicates", func(t *testing.T) {
+		ringKeys := []PublicKey{carolPub, alicePub, bobPub, alicePub}
+		canonical := CanonicalRing(ringKeys)
+		assert.Len(t, canonical, 3)
+		assert.True(t, IsCanonicalRing(canonical))
+		assert.False(t, IsCanonicalRing(ringKeys))
+
+		for i := 1; i < len(canonical); i++ {
+			assert.Equal(t, -1, bytes.Compare(canonical[i-1], canonical[i]))
+		}
+
+		// The input ring should not be modified.
+		assert.Equal(t, []PublicKey{carolPub, alicePub, bobPub, alicePub}, ringKeys)
+	})
+
+	t.Run("Is independent of the input order", func(t *testing.T) {
+		r1 := CanonicalRing([]PublicKey{alicePub, bobPub, carolPub})
+		r2 := CanonicalRing([]PublicKey{carolPub, bobPub, alicePub})
+		assert.Equal(t, r1, r2)
+	})
+
+	t.Run("Signs with canonical ring", func(t *testing.T) {
+		message := []byte("me + others")
+		sig, err := alicePriv.SignCanonical(nil, message, []PublicKey{alicePub, bobPub, carolPub})
+		assert.NoError(t, err, "SignCanonical()")
+		assert.True(t, sig.IsCanonical())
+		assert.True(t, sig.VerifyCanonical(message))
+		assert.Equal(t, CanonicalRing([]PublicKey{alicePub, bobPub, carolPub}), sig.Ring())
+	})
+
+	t.Run("Rejects non-canonical ring", func(t *testing.T) {
+		message := []byte("me + others")
+		ringKeys := CanonicalRing([]PublicKey{alicePub, bobPub, carolPub})
+		ringKeys[0], ringKeys[2] = ringKeys[2], ringKeys[0]
+
+		sig, err := alicePriv.SignAuto(nil, message, ringKeys)
+		assert.NoError(t, err, "SignAuto()")
+		assert.True(t, sig.Verify(message))
+		assert.False(t, sig.VerifyCanonical(message))
+	})
+}
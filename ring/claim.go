@@ -0,0 +1,301 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotClaimable is returned when claiming a signature that wasn't
+	// produced with SignClaimable.
+	ErrNotClaimable = errors.New("the signature can't be claimed")
+
+	// ErrNotSigner is returned when claiming a signature produced by
+	// another ring member.
+	ErrNotSigner = errors.New("the key did not produce the signature")
+
+	// ErrInvalidClaim is returned when a claim doesn't match the signature.
+	ErrInvalidClaim = errors.New("invalid claim")
+)
+
+// claimDomain separates claim hashes from other hashes.
+const claimDomain = "ring-signatures/claim/v1"
+
+// A claimable signature contains a commitment to its signer:
+//	* Let x be the signer's private key and P = x*G its public key
+//	* Randomly choose a salt and let H = Hp(salt)
+//	* The commitment is J = x*H: it hides P since H is fresh for every signature
+//
+// Like the key image of a linkable signature, the commitment is part of the
+// ring equation, which proves that it was made with the key closing the ring:
+//	* e(i+1 % R) = H(m || s(i)*G + e(i)*P(i) || s(i)*H + e(i)*J)
+//
+// The signer thus can't commit to another ring member's key, even if that
+// member colludes. To claim the signature, the signer reveals P along with
+// a Chaum-Pedersen proof that P and J have the same discrete logarithm.
+
+// claimCommitment is the commitment to the signer of a claimable signature.
+type claimCommitment struct {
+	salt       []byte
+	commitment []byte
+}
+
+// commit creates a commitment to the signer.
+func (sk PrivateKey) commit(rand io.Reader) (*claimCommitment, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cc := &claimCommitment{salt: salt}
+	curve := elliptic.P384()
+	hx, hy := cc.base(curve)
+	jx, jy := curve.ScalarMult(hx, hy, sk)
+	cc.commitment = elliptic.Marshal(curve, jx, jy)
+
+	return cc, nil
+}
+
+// base returns the point H the commitment is computed from.
+func (cc *claimCommitment) base(curve elliptic.Curve) (*big.Int, *big.Int) {
+	return hashToPoint(curve, append([]byte(claimDomain), cc.salt...))
+}
+
+// noncePoint computes k*H, the commitment's part of the challenge that
+// initializes the ring.
+// Without commitment, it returns nil.
+func (cc *claimCommitment) noncePoint(curve elliptic.Curve, k []byte) []byte {
+	if cc == nil {
+		return nil
+	}
+
+	hx, hy := cc.base(curve)
+	x, y := curve.ScalarMult(hx, hy, k)
+	return elliptic.Marshal(curve, x, y)
+}
+
+// ringPoint computes s*H + e*J, the commitment's part of the challenges
+// along the ring.
+// Without commitment, it returns nil.
+func (cc *claimCommitment) ringPoint(curve elliptic.Curve, s, e []byte) ([]byte, error) {
+	if cc == nil {
+		return nil, nil
+	}
+
+	jx, jy := elliptic.Unmarshal(curve, cc.commitment)
+	if jx == nil {
+		return nil, ErrInvalidSignature
+	}
+
+	hx, hy := cc.base(curve)
+	x, y := addMult(curve, hx, hy, s, jx, jy, e)
+	return elliptic.Marshal(curve, x, y), nil
+}
+
+// bind binds the commitment to the message.
+// Without commitment, the message is left unchanged.
+func (cc *claimCommitment) bind(message []byte) []byte {
+	if cc == nil {
+		return message
+	}
+
+	var b []byte
+	b = appendBytes(b, []byte(claimDomain))
+	b = appendBytes(b, cc.salt)
+	b = appendBytes(b, cc.commitment)
+	return appendBytes(b, message)
+}
+
+// SignClaimable creates a ring signature for the given message that the
+// signer can later claim (see Claim).
+// The signature doesn't reveal the signer until they claim it.
+func (sk PrivateKey) SignClaimable(
+	rand io.Reader,
+	message []byte,
+	ringKeys []PublicKey,
+	signerIndex int,
+) (*Signature, error) {
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	claim, err := sk.commit(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	return sk.sign(rand, message, ringKeys, signerIndex, nil, claim)
+}
+
+// IsClaimable returns true if the signer can claim the signature.
+func (sig *Signature) IsClaimable() bool {
+	return sig.claim != nil
+}
+
+// Claim proves that a public key produced a signature.
+type Claim struct {
+	signer PublicKey
+	c      []byte
+	s      []byte
+}
+
+// Claim produces a proof that the private key produced the signature.
+// Revealing the claim reveals the signer, but not the other ring members.
+func (sk PrivateKey) Claim(sig *Signature) (*Claim, error) {
+	if sig == nil || sig.claim == nil {
+		return nil, ErrNotClaimable
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+
+	pk := sk.Public()
+	hx, hy := sig.claim.base(curve)
+	jx, jy := curve.ScalarMult(hx, hy, sk)
+	if !bytes.Equal(elliptic.Marshal(curve, jx, jy), sig.claim.commitment) {
+		return nil, ErrNotSigner
+	}
+
+	// The nonce is derived from the private key and the signature, so
+	// that claiming doesn't need randomness.
+	mac := hmac.New(sha512.New, sk)
+	mac.Write([]byte(claimDomain))
+	mac.Write(sig.e)
+	mac.Write(sig.claim.commitment)
+	k := new(big.Int).SetBytes(mac.Sum(nil))
+	k.Mod(k, n)
+	if k.Sign() == 0 {
+		return nil, errors.New("could not produce claim")
+	}
+
+	ax, ay := curve.ScalarBaseMult(k.Bytes())
+	bx, by := curve.ScalarMult(hx, hy, k.Bytes())
+	c := claimChallenge(curve, sig, pk, ax, ay, bx, by)
+
+	s := new(big.Int).Mul(new(big.Int).SetBytes(c), new(big.Int).SetBytes(sk))
+	s.Sub(k, s)
+	s.Mod(s, n)
+
+	return &Claim{signer: pk, c: c, s: s.Bytes()}, nil
+}
+
+// Signer returns the public key of the claimed signer.
+func (cl *Claim) Signer() PublicKey {
+	return cl.signer
+}
+
+// VerifyClaim verifies the validity of the message signature and that
+// the claim's signer produced it.
+func (sig *Signature) VerifyClaim(message []byte, claim *Claim) error {
+	if !sig.Verify(message) {
+		return ErrInvalidSignature
+	}
+
+	if sig.claim == nil {
+		return ErrNotClaimable
+	}
+
+	if claim == nil || len(claim.c) == 0 {
+		return ErrInvalidClaim
+	}
+
+	if !NewKeySet(sig.ring...).Contains(claim.signer) {
+		return ErrSignerNotInRing
+	}
+
+	curve := elliptic.P384()
+	px, py := elliptic.Unmarshal(curve, claim.signer)
+	if px == nil {
+		return ErrInvalidPublicKey
+	}
+
+	jx, jy := elliptic.Unmarshal(curve, sig.claim.commitment)
+	if jx == nil {
+		return ErrInvalidClaim
+	}
+
+	hx, hy := sig.claim.base(curve)
+	ax, ay := addMult(curve, nil, nil, claim.s, px, py, claim.c)
+	bx, by := addMult(curve, hx, hy, claim.s, jx, jy, claim.c)
+	if !bytes.Equal(claim.c, claimChallenge(curve, sig, claim.signer, ax, ay, bx, by)) {
+		return ErrInvalidClaim
+	}
+
+	return nil
+}
+
+// claimChallenge computes the challenge of the claim's Chaum-Pedersen proof.
+func claimChallenge(curve elliptic.Curve, sig *Signature, pk PublicKey, ax, ay, bx, by *big.Int) []byte {
+	h := sha256.New()
+	h.Write([]byte(claimDomain))
+	writeLengthPrefixed(h, sig.e)
+	writeLengthPrefixed(h, sig.claim.salt)
+	writeLengthPrefixed(h, sig.claim.commitment)
+	writeLengthPrefixed(h, pk)
+	h.Write(elliptic.Marshal(curve, ax, ay))
+	h.Write(elliptic.Marshal(curve, bx, by))
+	return h.Sum(nil)
+}
+
+// Encode encodes a claim to a friendly string representation.
+func (cl *Claim) Encode() (string, error) {
+	b, err := json.Marshal(struct {
+		P []byte
+		C []byte
+		S []byte
+	}{
+		P: cl.signer,
+		C: cl.c,
+		S: cl.s,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a claim from its friendly string representation.
+// It rejects claims exceeding the limits of DefaultDecodeOptions.
+func (cl *Claim) Decode(data string) error {
+	return cl.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes a claim from its friendly string representation
+// and checks it against the given limits.
+func (cl *Claim) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	unmarshalled := struct {
+		P []byte
+		C []byte
+		S []byte
+	}{}
+	err = opts.unmarshalJSON(b, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkProof(unmarshalled.P, unmarshalled.C, unmarshalled.S); err != nil {
+		return err
+	}
+
+	cl.signer = unmarshalled.P
+	cl.c = unmarshalled.C
+	cl.s = unmarshalled.S
+
+	return nil
+}
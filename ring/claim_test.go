@@ -0,0 +1,112 @@
+package ring
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaim(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, bobPriv := Generate(nil)
+	carolPub, carolPriv := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub, carolPub}
+	message := []byte("LGTM")
+
+	t.Run("Claims a signature", func(t *testing.T) {
+		sig, err := bobPriv.SignClaimable(nil, message, ringKeys, 1)
+		assert.NoError(t, err, "SignClaimable()")
+		assert.True(t, sig.IsClaimable())
+		assert.True(t, sig.Verify(message))
+
+		claim, err := bobPriv.Claim(sig)
+		assert.NoError(t, err, "Claim()")
+		assert.Equal(t, bobPub, claim.Signer())
+		assert.NoError(t, sig.VerifyClaim(message, claim))
+		assert.Equal(t, ErrInvalidSignature, sig.VerifyClaim([]byte("NACK"), claim))
+	})
+
+	t.Run("Non-signers can't claim", func(t *testing.T) {
+		sig, err := bobPriv.SignClaimable(nil, message, ringKeys, 1)
+		assert.NoError(t, err, "SignClaimable()")
+
+		_, err = alicePriv.Claim(sig)
+		assert.Equal(t, ErrNotSigner, err)
+
+		_, err = carolPriv.Claim(sig)
+		assert.Equal(t, ErrNotSigner, err)
+
+		// A claim for another signature doesn't apply.
+		other, err := alicePriv.SignClaimable(nil, message, ringKeys, 0)
+		assert.NoError(t, err, "SignClaimable()")
+		aliceClaim, err := alicePriv.Claim(other)
+		assert.NoError(t, err, "Claim()")
+		assert.Equal(t, ErrInvalidClaim, sig.VerifyClaim(message, aliceClaim))
+
+		// A claim can't be redirected to another key.
+		claim, err := bobPriv.Claim(sig)
+		assert.NoError(t, err, "Claim()")
+		forged := *claim
+		forged.signer = alicePub
+		assert.Equal(t, ErrInvalidClaim, sig.VerifyClaim(message, &forged))
+	})
+
+	t.Run("Colluding members can't claim", func(t *testing.T) {
+		// Bob signs but commits to Alice's key, with her help.
+		colluded, err := alicePriv.commit(crand.Reader)
+		assert.NoError(t, err, "commit()")
+
+		sig, err := bobPriv.sign(nil, message, ringKeys, 1, nil, colluded)
+		assert.NoError(t, err, "sign()")
+		assert.False(t, sig.Verify(message))
+
+		claim, err := alicePriv.Claim(sig)
+		assert.NoError(t, err, "Claim()")
+		assert.Equal(t, ErrInvalidSignature, sig.VerifyClaim(message, claim))
+	})
+
+	t.Run("Regular signatures can't be claimed", func(t *testing.T) {
+		sig, err := bobPriv.Sign(nil, message, ringKeys, 1)
+		assert.NoError(t, err, "Sign()")
+		assert.False(t, sig.IsClaimable())
+
+		_, err = bobPriv.Claim(sig)
+		assert.Equal(t, ErrNotClaimable, err)
+	})
+
+	t.Run("The commitment is signed", func(t *testing.T) {
+		sig, err := bobPriv.SignClaimable(nil, message, ringKeys, 1)
+		assert.NoError(t, err, "SignClaimable()")
+
+		other, err := alicePriv.SignClaimable(nil, message, ringKeys, 0)
+		assert.NoError(t, err, "SignClaimable()")
+
+		sig.claim = other.claim
+		assert.False(t, sig.Verify(message))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := carolPriv.SignClaimable(nil, message, ringKeys, 2)
+		assert.NoError(t, err, "SignClaimable()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &Signature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.IsClaimable())
+		assert.True(t, decoded.Verify(message))
+
+		claim, err := carolPriv.Claim(decoded)
+		assert.NoError(t, err, "Claim()")
+
+		encoded, err = claim.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decodedClaim := &Claim{}
+		assert.NoError(t, decodedClaim.Decode(encoded), "Decode()")
+		assert.Equal(t, carolPub, decodedClaim.Signer())
+		assert.NoError(t, sig.VerifyClaim(message, decodedClaim))
+	})
+}
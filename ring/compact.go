@@ -0,0 +1,537 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// compactDomain separates compact signature hashes from other hashes.
+const compactDomain = "ring-signatures/compact/v1"
+
+// CompactSignature is a ring signature whose size grows logarithmically
+// with the size of the ring (Groth-Kohlweiss one-out-of-many proof).
+// Unlike Signature, it doesn't contain the ring: verifiers need to know
+// the ring the signature was produced with.
+type CompactSignature struct {
+	cl [][]byte
+	ca [][]byte
+	cb [][]byte
+	cd [][]byte
+	f  [][]byte
+	za [][]byte
+	zb [][]byte
+	zd []byte
+}
+
+// Compact signing algorithm (Groth-Kohlweiss, binary decomposition):
+//	* Let H be a curve point nobody knows the discrete log of
+//	* Let Com(v, r) = v*H + r*G: every public key P(i) = x(i)*G is a
+//	  commitment to 0, and the signer knows the randomness x(l) of P(l)
+//	* Pad the ring to R = 2^m keys by repeating the last key
+//	* Let l(0),...,l(m-1) be the bits of the signer index l
+//	* for j := 0; j < m; j++:
+//		* Randomly choose r(j), a(j), s(j), t(j), rho(j) in [1:N-1]
+//		* cl(j) = Com(l(j), r(j)), ca(j) = Com(a(j), s(j)), cb(j) = Com(l(j)*a(j), t(j))
+//	* Let f(j,1)(X) = l(j)*X + a(j), f(j,0)(X) = X - f(j,1)(X)
+//	* Let p(i)(X) = f(0,i(0))(X)*...*f(m-1,i(m-1))(X) = [i = l]*X^m + sum(p(i,k)*X^k)
+//	* for k := 0; k < m; k++:
+//		* cd(k) = sum(p(i,k)*P(i)) + Com(0, rho(k))
+//	* Compute the challenge x = H(P(0),...,P(R-1) || m || cl || ca || cb || cd)
+//	* for j := 0; j < m; j++:
+//		* f(j) = l(j)*x + a(j)
+//		* za(j) = r(j)*x + s(j)
+//		* zb(j) = r(j)*(x - f(j)) + t(j)
+//	* zd = x(l)*x^m - sum(rho(k)*x^k)
+//	* Output signature: (cl, ca, cb, cd, f, za, zb, zd)
+//
+// Verifiers check that, for every j:
+//	* x*cl(j) + ca(j) = Com(f(j), za(j))
+//	* (x - f(j))*cl(j) + cb(j) = Com(0, zb(j))
+// and that sum(p(i)(x)*P(i)) - sum(x^k*cd(k)) = Com(0, zd).
+
+// SignCompact creates a compact ring signature for the given message.
+// The public key at the signer index must match the private key.
+func (sk PrivateKey) SignCompact(
+	rand io.Reader,
+	message []byte,
+	ringKeys []PublicKey,
+	signerIndex int,
+) (*CompactSignature, error) {
+	if len(message) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if signerIndex < 0 || len(ringKeys) <= signerIndex {
+		return nil, ErrInvalidSignerIndex
+	}
+
+	if len(ringKeys) < 2 {
+		return nil, ErrRingTooSmall
+	}
+
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if !bytes.Equal(sk.Public(), ringKeys[signerIndex]) {
+		return nil, ErrSignerMismatch
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+	hx, hy := compactGenerator(curve)
+
+	keys, err := padRing(curve, ringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	m := bitLength(len(ringKeys))
+	bits := make([]*big.Int, m)
+	r := make([]*big.Int, m)
+	a := make([]*big.Int, m)
+	s := make([]*big.Int, m)
+	t := make([]*big.Int, m)
+	rho := make([]*big.Int, m)
+	for j := 0; j < m; j++ {
+		bits[j] = big.NewInt(int64((signerIndex >> uint(j)) & 1))
+		for _, v := range []**big.Int{&r[j], &a[j], &s[j], &t[j], &rho[j]} {
+			b, err := randomParam(curve, rand)
+			if err != nil {
+				return nil, err
+			}
+
+			*v = new(big.Int).SetBytes(b)
+		}
+	}
+
+	sig := &CompactSignature{
+		cl: make([][]byte, m),
+		ca: make([][]byte, m),
+		cb: make([][]byte, m),
+		cd: make([][]byte, m),
+		f:  make([][]byte, m),
+		za: make([][]byte, m),
+		zb: make([][]byte, m),
+	}
+
+	for j := 0; j < m; j++ {
+		sig.cl[j] = commit(curve, hx, hy, bits[j], r[j])
+		sig.ca[j] = commit(curve, hx, hy, a[j], s[j])
+		sig.cb[j] = commit(curve, hx, hy, new(big.Int).Mul(bits[j], a[j]), t[j])
+	}
+
+	coeffs := ringPolynomial(curve, keys, signerIndex, a)
+	for k := 0; k < m; k++ {
+		rx, ry := curve.ScalarBaseMult(rho[k].Bytes())
+		x, y := curve.Add(coeffs[k].x, coeffs[k].y, rx, ry)
+		sig.cd[k] = elliptic.Marshal(curve, x, y)
+	}
+
+	x := sig.challenge(ringKeys, message)
+
+	for j := 0; j < m; j++ {
+		f := new(big.Int).Mul(bits[j], x)
+		f.Add(f, a[j])
+		f.Mod(f, n)
+		sig.f[j] = f.Bytes()
+
+		za := new(big.Int).Mul(r[j], x)
+		za.Add(za, s[j])
+		za.Mod(za, n)
+		sig.za[j] = za.Bytes()
+
+		zb := new(big.Int).Sub(x, f)
+		zb.Mul(zb, r[j])
+		zb.Add(zb, t[j])
+		zb.Mod(zb, n)
+		sig.zb[j] = zb.Bytes()
+	}
+
+	zd := new(big.Int).Exp(x, big.NewInt(int64(m)), n)
+	zd.Mul(zd, new(big.Int).SetBytes(sk))
+	xk := big.NewInt(1)
+	for k := 0; k < m; k++ {
+		zd.Sub(zd, new(big.Int).Mul(rho[k], xk))
+		xk.Mul(xk, x)
+		xk.Mod(xk, n)
+	}
+
+	zd.Mod(zd, n)
+	sig.zd = zd.Bytes()
+
+	return sig, nil
+}
+
+// Verify verifies the validity of the message signature for the given ring.
+// It does not detail why the signature validation failed.
+func (sig *CompactSignature) Verify(message []byte, ringKeys []PublicKey) bool {
+	if sig == nil || len(ringKeys) < 2 {
+		return false
+	}
+
+	m := bitLength(len(ringKeys))
+	if len(sig.cl) != m || len(sig.ca) != m || len(sig.cb) != m || len(sig.cd) != m ||
+		len(sig.f) != m || len(sig.za) != m || len(sig.zb) != m {
+		return false
+	}
+
+	if sig.checkScalars() != nil {
+		return false
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+	hx, hy := compactGenerator(curve)
+	x := sig.challenge(ringKeys, message)
+
+	f := make([]*big.Int, m)
+	for j := 0; j < m; j++ {
+		f[j] = new(big.Int).SetBytes(sig.f[j])
+
+		clx, cly := elliptic.Unmarshal(curve, sig.cl[j])
+		cax, cay := elliptic.Unmarshal(curve, sig.ca[j])
+		cbx, cby := elliptic.Unmarshal(curve, sig.cb[j])
+		if clx == nil || cax == nil || cbx == nil {
+			return false
+		}
+
+		// x*cl(j) + ca(j) = Com(f(j), za(j))
+		lx, ly := curve.ScalarMult(clx, cly, x.Bytes())
+		lx, ly = curve.Add(lx, ly, cax, cay)
+		if !bytes.Equal(elliptic.Marshal(curve, lx, ly), commit(curve, hx, hy, f[j], new(big.Int).SetBytes(sig.za[j]))) {
+			return false
+		}
+
+		// (x - f(j))*cl(j) + cb(j) = Com(0, zb(j))
+		e := new(big.Int).Sub(x, f[j])
+		e.Mod(e, n)
+		lx, ly = curve.ScalarMult(clx, cly, e.Bytes())
+		lx, ly = curve.Add(lx, ly, cbx, cby)
+		rx, ry := curve.ScalarBaseMult(sig.zb[j])
+		if lx.Cmp(rx) != 0 || ly.Cmp(ry) != 0 {
+			return false
+		}
+	}
+
+	// sum(p(i)(x)*P(i)) = Com(0, zd) + sum(x^k*cd(k))
+	scalars := ringScalars(n, x, f, len(ringKeys))
+	lx, ly := new(big.Int), new(big.Int)
+	for i, pk := range ringKeys {
+		px, py := elliptic.Unmarshal(curve, pk)
+		if px == nil {
+			return false
+		}
+
+		px, py = curve.ScalarMult(px, py, scalars[i].Bytes())
+		lx, ly = curve.Add(lx, ly, px, py)
+	}
+
+	rx, ry := curve.ScalarBaseMult(sig.zd)
+	xk := big.NewInt(1)
+	for k := 0; k < m; k++ {
+		cdx, cdy := elliptic.Unmarshal(curve, sig.cd[k])
+		if cdx == nil {
+			return false
+		}
+
+		cdx, cdy = curve.ScalarMult(cdx, cdy, xk.Bytes())
+		rx, ry = curve.Add(rx, ry, cdx, cdy)
+		xk.Mul(xk, x)
+		xk.Mod(xk, n)
+	}
+
+	return lx.Cmp(rx) == 0 && ly.Cmp(ry) == 0
+}
+
+// checkScalars checks that the responses of the proof are in [0:N-1].
+// Otherwise a response and the same response plus N would both verify.
+func (sig *CompactSignature) checkScalars() error {
+	n := elliptic.P384().Params().N
+	for _, field := range []struct {
+		name    string
+		scalars [][]byte
+	}{{"f", sig.f}, {"za", sig.za}, {"zb", sig.zb}, {"zd", [][]byte{sig.zd}}} {
+		for i, s := range field.scalars {
+			if new(big.Int).SetBytes(s).Cmp(n) >= 0 {
+				return errors.Wrapf(ErrMalformedSignature, "invalid %s at index %d", field.name, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// challenge computes the Fiat-Shamir challenge of the proof.
+func (sig *CompactSignature) challenge(ringKeys []PublicKey, message []byte) *big.Int {
+	h := sha256.New()
+	h.Write([]byte(compactDomain))
+	writeLengthPrefixed(h, message)
+	for _, pk := range ringKeys {
+		writeLengthPrefixed(h, pk)
+	}
+
+	for _, points := range [][][]byte{sig.cl, sig.ca, sig.cb, sig.cd} {
+		for _, p := range points {
+			writeLengthPrefixed(h, p)
+		}
+	}
+
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// compactGenerator returns the commitment generator H.
+func compactGenerator(curve elliptic.Curve) (*big.Int, *big.Int) {
+	return hashToPoint(curve, []byte(compactDomain))
+}
+
+// commit computes the commitment v*H + r*G.
+func commit(curve elliptic.Curve, hx, hy *big.Int, v, r *big.Int) []byte {
+	n := curve.Params().N
+	x1, y1 := curve.ScalarMult(hx, hy, new(big.Int).Mod(v, n).Bytes())
+	x2, y2 := curve.ScalarBaseMult(new(big.Int).Mod(r, n).Bytes())
+	x, y := curve.Add(x1, y1, x2, y2)
+	return elliptic.Marshal(curve, x, y)
+}
+
+// bitLength returns the number of bits needed to index a ring of size n.
+func bitLength(n int) int {
+	m := 1
+	for 1<<uint(m) < n {
+		m++
+	}
+
+	return m
+}
+
+// point is an affine curve point; (0, 0) is the point at infinity.
+type point struct {
+	x, y *big.Int
+}
+
+// padRing decodes the ring and pads it to a power of two by repeating
+// the last key.
+func padRing(curve elliptic.Curve, ringKeys []PublicKey) ([]point, error) {
+	padded := make([]point, 1<<uint(bitLength(len(ringKeys))))
+	for i := range padded {
+		pk := ringKeys[len(ringKeys)-1]
+		if i < len(ringKeys) {
+			pk = ringKeys[i]
+		}
+
+		x, y := elliptic.Unmarshal(curve, pk)
+		if x == nil {
+			return nil, ErrInvalidPublicKey
+		}
+
+		padded[i] = point{x, y}
+	}
+
+	return padded, nil
+}
+
+// ringPolynomial computes the coefficients sum(p(i,k)*P(i)) of the
+// polynomial sum(p(i)(X)*P(i)), from degree 0 to m.
+// It folds the ring one bit at a time: combining the polynomials L and R
+// of two sibling subtrees at level j gives X*(l(j) ? R : L) + a(j)*(R - L),
+// which needs about 2*len(keys) scalar multiplications in total.
+func ringPolynomial(curve elliptic.Curve, keys []point, signerIndex int, a []*big.Int) []point {
+	params := curve.Params()
+	nodes := make([][]point, len(keys))
+	for i, k := range keys {
+		nodes[i] = []point{k}
+	}
+
+	for j := 0; len(nodes) > 1; j++ {
+		bit := (signerIndex >> uint(j)) & 1
+		next := make([][]point, len(nodes)/2)
+		for i := range next {
+			left, right := nodes[2*i], nodes[2*i+1]
+			selected := left
+			if bit == 1 {
+				selected = right
+			}
+
+			coeffs := make([]point, j+2)
+			for k := range coeffs {
+				x, y := new(big.Int), new(big.Int)
+				if k > 0 {
+					x, y = selected[k-1].x, selected[k-1].y
+				}
+
+				if k <= j {
+					// a(j)*(R - L)
+					ny := new(big.Int).Sub(params.P, left[k].y)
+					if left[k].x.Sign() == 0 && left[k].y.Sign() == 0 {
+						ny = left[k].y
+					}
+
+					dx, dy := curve.Add(right[k].x, right[k].y, left[k].x, ny)
+					dx, dy = curve.ScalarMult(dx, dy, a[j].Bytes())
+					x, y = curve.Add(x, y, dx, dy)
+				}
+
+				coeffs[k] = point{x, y}
+			}
+
+			next[i] = coeffs
+		}
+
+		nodes = next
+	}
+
+	return nodes[0]
+}
+
+// ringScalars computes the scalars p(i)(x) of every ring member.
+// The scalars of the padding keys are added to the last key's.
+func ringScalars(n, x *big.Int, f []*big.Int, ringSize int) []*big.Int {
+	// p(i)(x) is the product of f(j) if bit j of i is set, x - f(j) otherwise.
+	products := []*big.Int{big.NewInt(1)}
+	for j := range f {
+		notF := new(big.Int).Sub(x, f[j])
+		notF.Mod(notF, n)
+
+		next := make([]*big.Int, 2*len(products))
+		for i, p := range products {
+			next[i] = new(big.Int).Mul(p, notF)
+			next[i].Mod(next[i], n)
+			next[i+len(products)] = new(big.Int).Mul(p, f[j])
+			next[i+len(products)].Mod(next[i+len(products)], n)
+		}
+
+		products = next
+	}
+
+	scalars := products[:ringSize]
+	for _, p := range products[ringSize:] {
+		scalars[ringSize-1].Add(scalars[ringSize-1], p)
+	}
+
+	scalars[ringSize-1].Mod(scalars[ringSize-1], n)
+	return scalars
+}
+
+// Marshal marshals a compact signature to a byte representation.
+func (sig *CompactSignature) Marshal() ([]byte, error) {
+	return json.Marshal(struct {
+		L  [][]byte
+		A  [][]byte
+		B  [][]byte
+		D  [][]byte
+		F  [][]byte
+		ZA [][]byte
+		ZB [][]byte
+		ZD []byte
+	}{
+		L:  sig.cl,
+		A:  sig.ca,
+		B:  sig.cb,
+		D:  sig.cd,
+		F:  sig.f,
+		ZA: sig.za,
+		ZB: sig.zb,
+		ZD: sig.zd,
+	})
+}
+
+// Unmarshal unmarshals a compact signature from its byte representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
+func (sig *CompactSignature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, DefaultDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals a compact signature from its byte
+// representation and checks it against the given limits.
+// The ring isn't part of the signature: the maximum ring size bounds the
+// number of bits of the signer index it proves.
+func (sig *CompactSignature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
+	unmarshalled := struct {
+		L  [][]byte
+		A  [][]byte
+		B  [][]byte
+		D  [][]byte
+		F  [][]byte
+		ZA [][]byte
+		ZB [][]byte
+		ZD []byte
+	}{}
+	err := opts.unmarshalJSON(data, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	m := len(unmarshalled.F)
+	if opts.MaxRingSize > 0 && m > bitLength(opts.MaxRingSize) {
+		return ErrRingTooLarge
+	}
+
+	if opts.StrictFields {
+		for _, field := range [][][]byte{unmarshalled.L, unmarshalled.A, unmarshalled.B, unmarshalled.D, unmarshalled.ZA, unmarshalled.ZB} {
+			if len(field) != m {
+				return errors.Wrap(ErrMalformedSignature, "there should be one value per bit of the signer index")
+			}
+		}
+	}
+
+	sig.cl = unmarshalled.L
+	sig.ca = unmarshalled.A
+	sig.cb = unmarshalled.B
+	sig.cd = unmarshalled.D
+	sig.f = unmarshalled.F
+	sig.za = unmarshalled.ZA
+	sig.zb = unmarshalled.ZB
+	sig.zd = unmarshalled.ZD
+
+	if err := sig.checkScalars(); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding(data, sig.Marshal)
+}
+
+// Encode encodes a compact signature to a friendly string representation.
+func (sig *CompactSignature) Encode() (string, error) {
+	b, err := sig.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a compact signature from its friendly string representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
+func (sig *CompactSignature) Decode(data string) error {
+	return sig.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes a compact signature from its friendly string
+// representation and checks it against the given limits.
+func (sig *CompactSignature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
+}
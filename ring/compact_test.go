@@ -0,0 +1,115 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompact(t *testing.T) {
+	var ringKeys []PublicKey
+	var privKeys []PrivateKey
+	for i := 0; i < 9; i++ {
+		pk, sk := Generate(nil)
+		ringKeys = append(ringKeys, pk)
+		privKeys = append(privKeys, sk)
+	}
+
+	message := []byte("hello")
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := privKeys[0].SignCompact(nil, nil, ringKeys, 0)
+		assert.EqualError(t, err, ErrEmptyMessage.Error())
+
+		_, err = privKeys[0].SignCompact(nil, message, ringKeys[:1], 0)
+		assert.EqualError(t, err, ErrRingTooSmall.Error())
+
+		_, err = privKeys[0].SignCompact(nil, message, ringKeys, 9)
+		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
+
+		_, err = privKeys[0].SignCompact(nil, message, ringKeys, 1)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+	})
+
+	t.Run("Signs and verifies", func(t *testing.T) {
+		for _, size := range []int{2, 3, 4, 5, 9} {
+			for i := 0; i < size; i++ {
+				sig, err := privKeys[i].SignCompact(nil, message, ringKeys[:size], i)
+				assert.NoError(t, err, "SignCompact()")
+				assert.True(t, sig.Verify(message, ringKeys[:size]), "Verify(%d, %d)", size, i)
+				assert.False(t, sig.Verify([]byte("hell0"), ringKeys[:size]))
+			}
+		}
+	})
+
+	t.Run("Rejects another ring", func(t *testing.T) {
+		sig, err := privKeys[2].SignCompact(nil, message, ringKeys[:5], 2)
+		assert.NoError(t, err, "SignCompact()")
+
+		assert.False(t, sig.Verify(message, ringKeys[:6]))
+		assert.False(t, sig.Verify(message, ringKeys[1:6]))
+		assert.False(t, sig.Verify(message, ringKeys[:4]))
+
+		// A signature from a non-member can't be made valid for the ring.
+		outsiderPub, outsiderPriv := Generate(nil)
+		withOutsider := append([]PublicKey{outsiderPub}, ringKeys[1:5]...)
+		sig, err = outsiderPriv.SignCompact(nil, message, withOutsider, 0)
+		assert.NoError(t, err, "SignCompact()")
+		assert.False(t, sig.Verify(message, ringKeys[:5]))
+	})
+
+	t.Run("Size grows logarithmically", func(t *testing.T) {
+		small, err := privKeys[0].SignCompact(nil, message, ringKeys[:2], 0)
+		assert.NoError(t, err, "SignCompact()")
+		large, err := privKeys[0].SignCompact(nil, message, ringKeys, 0)
+		assert.NoError(t, err, "SignCompact()")
+
+		assert.Len(t, small.f, 1)
+		assert.Len(t, large.f, 4)
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := privKeys[3].SignCompact(nil, message, ringKeys, 3)
+		assert.NoError(t, err, "SignCompact()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &CompactSignature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify(message, ringKeys))
+
+		decoded.zd = decoded.f[0]
+		assert.False(t, decoded.Verify(message, ringKeys))
+	})
+
+	t.Run("Rejects responses out of range", func(t *testing.T) {
+		n := elliptic.P384().Params().N
+		sig, err := privKeys[1].SignCompact(nil, message, ringKeys[:4], 1)
+		assert.NoError(t, err, "SignCompact()")
+
+		for _, field := range []string{"za", "zb", "zd"} {
+			tampered := *sig
+			tampered.za = append([][]byte(nil), sig.za...)
+			tampered.zb = append([][]byte(nil), sig.zb...)
+			switch field {
+			case "za":
+				tampered.za[0] = new(big.Int).Add(new(big.Int).SetBytes(sig.za[0]), n).Bytes()
+			case "zb":
+				tampered.zb[1] = new(big.Int).Add(new(big.Int).SetBytes(sig.zb[1]), n).Bytes()
+			case "zd":
+				tampered.zd = new(big.Int).Add(new(big.Int).SetBytes(sig.zd), n).Bytes()
+			}
+
+			assert.False(t, tampered.Verify(message, ringKeys[:4]), field)
+
+			encoded, err := tampered.Encode()
+			assert.NoError(t, err, "Encode()")
+			err = (&CompactSignature{}).Decode(encoded)
+			assert.Equal(t, ErrMalformedSignature, errors.Cause(err), field)
+		}
+	})
+}
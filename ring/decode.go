@@ -0,0 +1,235 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrSignatureTooLong is returned when decoding a signature longer than
+	// the maximum length.
+	ErrSignatureTooLong = errors.New("the signature is too long")
+
+	// ErrRingTooLarge is returned when signing with a ring larger than
+	// MaxRingSize, or when decoding a signature whose ring contains more
+	// members than allowed.
+	ErrRingTooLarge = errors.New("the signature's ring is too large")
+
+	// ErrMalformedSignature is returned when a signature can't be decoded.
+	ErrMalformedSignature = errors.New("malformed signature")
+
+	// ErrNonCanonicalSignature is returned when strictly decoding a
+	// signature that isn't in its canonical encoding.
+	ErrNonCanonicalSignature = errors.New("the signature is not canonically encoded")
+)
+
+// DecodeOptions limits the signatures accepted when decoding.
+// Verifying a signature costs curve operations for every ring member, so
+// signatures submitted by untrusted users should be decoded with limits.
+// Zero values disable the limits.
+type DecodeOptions struct {
+	// MaxRingSize is the maximum number of ring members.
+	MaxRingSize int
+
+	// MaxLength is the maximum length of the encoded signature, in bytes.
+	MaxLength int
+
+	// StrictFields rejects unknown fields and fields that don't have the
+	// size of a public key, hash or scalar.
+	StrictFields bool
+
+	// StrictEncoding only accepts the canonical encoding of signatures,
+	// which is the one produced by Encode and Marshal: scalars are minimally
+	// encoded and in [1:N-1], and the JSON and base64 representations are
+	// byte for byte those of Marshal and Encode.
+	// Signatures then have a single valid encoding, so that it can be used
+	// to identify them. Scalars are only checked for Signature and
+	// LinkableSignature, and claims, openings and repudiations ignore this
+	// option.
+	StrictEncoding bool
+}
+
+// MaxRingSize is the maximum number of ring members signatures can be
+// produced with. DefaultDecodeOptions use the same limit, so that every
+// signature that can be produced can be decoded.
+const MaxRingSize = 1024
+
+// DefaultDecodeOptions are the options used by Decode and Unmarshal.
+var DefaultDecodeOptions = DecodeOptions{
+	MaxRingSize:  MaxRingSize,
+	MaxLength:    1 << 20,
+	StrictFields: true,
+}
+
+// defaultOptionsFor returns DefaultDecodeOptions, with a maximum length
+// large enough for signatures of MaxRingSize members whose keys and
+// responses take memberSize bytes.
+func defaultOptionsFor(memberSize int) DecodeOptions {
+	opts := DefaultDecodeOptions
+	// Base64 and JSON add less than a third of the raw size.
+	if length := 2 * MaxRingSize * memberSize; opts.MaxLength > 0 && length > opts.MaxLength {
+		opts.MaxLength = length
+	}
+
+	return opts
+}
+
+// scalarSize is the size of a P-384 scalar.
+const scalarSize = 48
+
+// decodeBase64 checks the length of a signature before decoding it.
+func (opts DecodeOptions) decodeBase64(data string) ([]byte, error) {
+	if opts.MaxLength > 0 && len(data) > opts.MaxLength {
+		return nil, ErrSignatureTooLong
+	}
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedSignature, err.Error())
+	}
+
+	return b, nil
+}
+
+// unmarshalJSON checks the length of a signature before unmarshalling it.
+func (opts DecodeOptions) unmarshalJSON(data []byte, v interface{}) error {
+	if opts.MaxLength > 0 && len(data) > opts.MaxLength {
+		return ErrSignatureTooLong
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if opts.StrictFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return errors.Wrap(ErrMalformedSignature, err.Error())
+	}
+
+	if opts.StrictFields && dec.More() {
+		return errors.Wrap(ErrMalformedSignature, "unexpected data after the signature")
+	}
+
+	return nil
+}
+
+// checkRingSize checks the number of ring members.
+func (opts DecodeOptions) checkRingSize(size int) error {
+	if opts.MaxRingSize > 0 && size > opts.MaxRingSize {
+		return ErrRingTooLarge
+	}
+
+	return nil
+}
+
+// checkResponses checks that there is one response per ring member.
+func (opts DecodeOptions) checkResponses(size int, responses [][]byte) error {
+	if opts.StrictFields && len(responses) != size {
+		return errors.Wrap(ErrMalformedSignature, "there should be one response per ring member")
+	}
+
+	return nil
+}
+
+// checkRing checks the ring and the scalars of its members.
+func (opts DecodeOptions) checkRing(ringKeys []PublicKey, s [][]byte) error {
+	if err := opts.checkRingSize(len(ringKeys)); err != nil {
+		return err
+	}
+
+	if !opts.StrictFields {
+		return nil
+	}
+
+	if len(s) != len(ringKeys) {
+		return errors.Wrap(ErrMalformedSignature, "there should be one scalar per ring member")
+	}
+
+	curve := elliptic.P384()
+	for i, pk := range ringKeys {
+		if x, _ := elliptic.Unmarshal(curve, pk); x == nil {
+			return errors.Wrapf(ErrMalformedSignature, "invalid public key at index %d", i)
+		}
+
+		if len(s[i]) == 0 || len(s[i]) > scalarSize {
+			return errors.Wrapf(ErrMalformedSignature, "invalid scalar at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+// checkEncoding checks that data is the canonical encoding of a signature.
+func (opts DecodeOptions) checkEncoding(data []byte, canonical func() ([]byte, error)) error {
+	if !opts.StrictEncoding {
+		return nil
+	}
+
+	b, err := canonical()
+	if err != nil || !bytes.Equal(b, data) {
+		return ErrNonCanonicalSignature
+	}
+
+	return nil
+}
+
+// checkScalars checks that scalars are minimally encoded and in [1:N-1].
+// Otherwise several scalars would map to the same curve point and a
+// signature would have several valid encodings.
+func checkScalars(s [][]byte) error {
+	n := elliptic.P384().Params().N
+	for i, si := range s {
+		if len(si) == 0 || si[0] == 0 || new(big.Int).SetBytes(si).Cmp(n) >= 0 {
+			return errors.Wrapf(ErrNonCanonicalSignature, "invalid scalar at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+// checkHash checks the size of a hash.
+func (opts DecodeOptions) checkHash(name string, h []byte) error {
+	if opts.StrictFields && len(h) != sha256.Size {
+		return errors.Wrapf(ErrMalformedSignature, "invalid %s", name)
+	}
+
+	return nil
+}
+
+// checkScalarSize checks the size of a scalar.
+func (opts DecodeOptions) checkScalarSize(name string, s []byte) error {
+	if opts.StrictFields && (len(s) == 0 || len(s) > scalarSize) {
+		return errors.Wrapf(ErrMalformedSignature, "invalid %s", name)
+	}
+
+	return nil
+}
+
+// checkProof checks the fields of a Chaum-Pedersen proof about a key:
+// claims, openings and repudiations.
+func (opts DecodeOptions) checkProof(pk, c, s []byte) error {
+	if err := opts.checkPoint("public key", pk); err != nil {
+		return err
+	}
+
+	if err := opts.checkHash("challenge", c); err != nil {
+		return err
+	}
+
+	return opts.checkScalarSize("response", s)
+}
+
+// checkPoint checks that a point is on the curve.
+func (opts DecodeOptions) checkPoint(name string, p []byte) error {
+	if x, _ := elliptic.Unmarshal(elliptic.P384(), p); opts.StrictFields && x == nil {
+		return errors.Wrapf(ErrMalformedSignature, "invalid %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,464 @@
+package ring
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	pubKeys, privKeys := GenerateKeys(4)
+	message := []byte("hostile")
+
+	sig, err := privKeys[2].Sign(nil, message, pubKeys, 2)
+	assert.NoError(t, err, "Sign()")
+
+	encoded, err := sig.Encode()
+	assert.NoError(t, err, "Encode()")
+
+	b, err := sig.Marshal()
+	assert.NoError(t, err, "Marshal()")
+
+	t.Run("Accepts valid signatures", func(t *testing.T) {
+		decoded := &Signature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify(message))
+	})
+
+	t.Run("Rejects long signatures", func(t *testing.T) {
+		decoded := &Signature{}
+		err := decoded.DecodeWithOptions(encoded, DecodeOptions{MaxLength: len(encoded) - 1})
+		assert.Equal(t, ErrSignatureTooLong, err)
+
+		err = decoded.UnmarshalWithOptions(b, DecodeOptions{MaxLength: len(b) - 1})
+		assert.Equal(t, ErrSignatureTooLong, err)
+
+		err = decoded.Decode(strings.Repeat("A", DefaultDecodeOptions.MaxLength+4))
+		assert.Equal(t, ErrSignatureTooLong, err)
+	})
+
+	t.Run("Rejects large rings", func(t *testing.T) {
+		decoded := &Signature{}
+		err := decoded.DecodeWithOptions(encoded, DecodeOptions{MaxRingSize: 3})
+		assert.Equal(t, ErrRingTooLarge, err)
+
+		assert.NoError(t, decoded.DecodeWithOptions(encoded, DecodeOptions{MaxRingSize: 4}))
+	})
+
+	t.Run("Rejects malformed signatures", func(t *testing.T) {
+		decoded := &Signature{}
+		err := decoded.Decode("not base64!")
+		assert.Equal(t, ErrMalformedSignature, errors.Cause(err))
+
+		err = decoded.Unmarshal([]byte("{\"R\": 42}"))
+		assert.Equal(t, ErrMalformedSignature, errors.Cause(err))
+	})
+
+	t.Run("Checks fields in strict mode", func(t *testing.T) {
+		withField := strings.Replace(string(b), "{", "{\"Z\":1,", 1)
+		withTrailer := string(b) + "{}"
+
+		invalidKey := &Signature{ring: []PublicKey{pubKeys[0], PublicKey("key")}, e: sig.e, s: sig.s[:2]}
+		longScalar := &Signature{ring: sig.ring, e: sig.e, s: [][]byte{sig.s[0], sig.s[1], sig.s[2], make([]byte, 49)}}
+		missingScalar := &Signature{ring: sig.ring, e: sig.e, s: sig.s[:3]}
+		shortHash := &Signature{ring: sig.ring, e: sig.e[:31], s: sig.s}
+
+		var malformed [][]byte
+		malformed = append(malformed, []byte(withField), []byte(withTrailer))
+		for _, s := range []*Signature{invalidKey, longScalar, missingScalar, shortHash} {
+			m, err := s.Marshal()
+			assert.NoError(t, err, "Marshal()")
+			malformed = append(malformed, m)
+		}
+
+		for _, m := range malformed {
+			decoded := &Signature{}
+			err := decoded.Unmarshal(m)
+			assert.Equal(t, ErrMalformedSignature, errors.Cause(err), string(m))
+
+			err = decoded.UnmarshalWithOptions(m, DecodeOptions{})
+			assert.NoError(t, err, string(m))
+		}
+	})
+
+	t.Run("Checks linkable signatures", func(t *testing.T) {
+		linkable, err := privKeys[1].SignLinkable(nil, message, pubKeys, 1, []byte("scope"))
+		assert.NoError(t, err, "SignLinkable()")
+
+		encoded, err := linkable.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &LinkableSignature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify(message))
+
+		err = decoded.DecodeWithOptions(encoded, DecodeOptions{MaxRingSize: 2})
+		assert.Equal(t, ErrRingTooLarge, err)
+
+		linkable.keyImage = []byte("image")
+		encoded, err = linkable.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		err = decoded.Decode(encoded)
+		assert.Equal(t, ErrMalformedSignature, errors.Cause(err))
+	})
+}
+
+// decoder is implemented by every signature and proof.
+type decoder interface {
+	DecodeWithOptions(data string, opts DecodeOptions) error
+}
+
+// encodedSamples returns the encoding of every signature and proof type
+// with a new decoder for it, and whether it contains a ring.
+func encodedSamples(t testing.TB, message []byte) map[string]struct {
+	encoded string
+	decoder func() decoder
+	hasRing bool
+} {
+	pubKeys, privKeys := GenerateKeys(4)
+	samples := make(map[string]struct {
+		encoded string
+		decoder func() decoder
+		hasRing bool
+	})
+
+	add := func(name string, encode func() (string, error), newDecoder func() decoder, hasRing bool) {
+		encoded, err := encode()
+		if err != nil {
+			t.Fatal(name, err)
+		}
+
+		samples[name] = struct {
+			encoded string
+			decoder func() decoder
+			hasRing bool
+		}{encoded, newDecoder, hasRing}
+	}
+
+	rsaKeys := make([]*rsa.PublicKey, 2)
+	rsaPriv := make([]*rsa.PrivateKey, 2)
+	for i := range rsaKeys {
+		sk, err := rsa.GenerateKey(crand.Reader, rsaMinBits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsaPriv[i], rsaKeys[i] = sk, &sk.PublicKey
+	}
+
+	rsaSig, err := SignRSA(nil, message, rsaKeys, 1, rsaPriv[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("rsa", rsaSig.Encode, func() decoder { return &RSASignature{} }, true)
+
+	mixedSig, err := SignMixed(nil, message, []crypto.PublicKey{pubKeys[0], rsaKeys[0]}, 0, privKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("mixed", mixedSig.Encode, func() decoder { return &MixedSignature{} }, true)
+
+	compactSig, err := privKeys[1].SignCompact(nil, message, pubKeys, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("compact", compactSig.Encode, func() decoder { return &CompactSignature{} }, true)
+
+	accountableSig, err := privKeys[2].SignAccountable(nil, message, pubKeys[:3], 2, pubKeys[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opening, err := privKeys[3].Open(nil, accountableSig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("accountable", accountableSig.Encode, func() decoder { return &AccountableSignature{} }, true)
+	add("opening", opening.Encode, func() decoder { return &Opening{} }, false)
+
+	claimable, err := privKeys[0].SignClaimable(nil, message, pubKeys, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claim, err := privKeys[0].Claim(claimable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("claim", claim.Encode, func() decoder { return &Claim{} }, false)
+
+	linkable, err := privKeys[0].SignLinkable(nil, message, pubKeys, 0, []byte("scope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := privKeys[1].Repudiate(linkable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("repudiation", rep.Encode, func() decoder { return &Repudiation{} }, false)
+
+	alicePub, alicePriv := GenerateLattice(nil)
+	bobPub, _ := GenerateLattice(nil)
+	latticeSig, err := alicePriv.Sign(nil, message, []LatticePublicKey{alicePub, bobPub}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add("lattice", latticeSig.Encode, func() decoder { return &LatticeSignature{} }, true)
+
+	return samples
+}
+
+func TestDecodeOptionsAllTypes(t *testing.T) {
+	for name, sample := range encodedSamples(t, []byte("hostile")) {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, sample.decoder().DecodeWithOptions(sample.encoded, DefaultDecodeOptions))
+
+			err := sample.decoder().DecodeWithOptions(sample.encoded, DecodeOptions{MaxLength: len(sample.encoded) - 1})
+			assert.Equal(t, ErrSignatureTooLong, err)
+
+			if sample.hasRing {
+				err = sample.decoder().DecodeWithOptions(sample.encoded, DecodeOptions{MaxRingSize: 1})
+				assert.Equal(t, ErrRingTooLarge, err)
+			}
+
+			// Unknown fields are rejected.
+			b, err := base64.StdEncoding.DecodeString(sample.encoded)
+			assert.NoError(t, err, "DecodeString()")
+			var fields map[string]interface{}
+			assert.NoError(t, json.Unmarshal(b, &fields), "json.Unmarshal()")
+			fields["Extra"] = 1
+			b, err = json.Marshal(fields)
+			assert.NoError(t, err, "json.Marshal()")
+			err = sample.decoder().DecodeWithOptions(base64.StdEncoding.EncodeToString(b), DefaultDecodeOptions)
+			assert.Equal(t, ErrMalformedSignature, errors.Cause(err))
+		})
+	}
+
+	t.Run("Rejects large RSA moduli", func(t *testing.T) {
+		n := new(big.Int).Lsh(big.NewInt(1), rsaMaxBits+8)
+		n.Add(n, big.NewInt(1))
+		large := x509.MarshalPKCS1PublicKey(&rsa.PublicKey{N: n, E: 65537})
+
+		b, err := json.Marshal(struct {
+			R [][]byte
+			V []byte
+			X [][]byte
+		}{R: [][]byte{large, large}, X: [][]byte{{1}, {1}}})
+		assert.NoError(t, err, "json.Marshal()")
+		assert.Equal(t, ErrRSAKeyTooLarge, (&RSASignature{}).Unmarshal(b))
+
+		der, err := x509.MarshalPKIXPublicKey(&rsa.PublicKey{N: n, E: 65537})
+		assert.NoError(t, err, "MarshalPKIXPublicKey()")
+		b, err = json.Marshal(struct {
+			R [][]byte
+			C []byte
+			S [][]byte
+		}{R: [][]byte{der, der}, C: make([]byte, 32), S: [][]byte{{1}, {1}}})
+		assert.NoError(t, err, "json.Marshal()")
+		assert.Equal(t, ErrRSAKeyTooLarge, (&MixedSignature{}).Unmarshal(b))
+	})
+}
+
+func TestStrictEncoding(t *testing.T) {
+	pubKeys, privKeys := GenerateKeys(3)
+	message := []byte("deduplicate me")
+	strict := DefaultDecodeOptions
+	strict.StrictEncoding = true
+
+	sig, err := privKeys[1].Sign(nil, message, pubKeys, 1)
+	assert.NoError(t, err, "Sign()")
+
+	encoded, err := sig.Encode()
+	assert.NoError(t, err, "Encode()")
+
+	b, err := sig.Marshal()
+	assert.NoError(t, err, "Marshal()")
+
+	n := elliptic.P384().Params().N
+
+	t.Run("Sign emits canonical signatures", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			sig, err := privKeys[i%3].Sign(nil, message, pubKeys, i%3)
+			assert.NoError(t, err, "Sign()")
+			assert.True(t, sig.VerifyStrict(message))
+
+			encoded, err := sig.Encode()
+			assert.NoError(t, err, "Encode()")
+			assert.NoError(t, (&Signature{}).DecodeWithOptions(encoded, strict))
+		}
+
+		attrs := Attributes{Timestamp: time.Now(), Context: "<ctx>", Claims: map[string]string{"b": "2", "a": "1"}}
+		sig, err := privKeys[0].SignWithAttributes(nil, message, pubKeys, 0, attrs)
+		assert.NoError(t, err, "SignWithAttributes()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+		assert.NoError(t, (&Signature{}).DecodeWithOptions(encoded, strict))
+
+		linkable, err := privKeys[2].SignLinkable(nil, message, pubKeys, 2, []byte("scope"))
+		assert.NoError(t, err, "SignLinkable()")
+		assert.True(t, linkable.VerifyStrict(message))
+
+		encoded, err = linkable.Encode()
+		assert.NoError(t, err, "Encode()")
+		assert.NoError(t, (&LinkableSignature{}).DecodeWithOptions(encoded, strict))
+	})
+
+	t.Run("Rejects scalars with leading zeros or greater than N", func(t *testing.T) {
+		for _, s := range [][]byte{
+			append([]byte{0}, sig.s[0]...),
+			new(big.Int).Add(new(big.Int).SetBytes(sig.s[0]), n).Bytes(),
+		} {
+			malleated := &Signature{ring: sig.ring, e: sig.e, s: [][]byte{s, sig.s[1], sig.s[2]}}
+			assert.True(t, malleated.Verify(message))
+			assert.False(t, malleated.VerifyStrict(message))
+
+			m, err := malleated.Marshal()
+			assert.NoError(t, err, "Marshal()")
+
+			err = (&Signature{}).UnmarshalWithOptions(m, DecodeOptions{StrictEncoding: true})
+			assert.Equal(t, ErrNonCanonicalSignature, errors.Cause(err))
+		}
+	})
+
+	t.Run("Rejects non-canonical JSON", func(t *testing.T) {
+		var fields map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(b, &fields))
+
+		reordered := fmt.Sprintf("{\"E\":%s,\"R\":%s,\"S\":%s}", fields["E"], fields["R"], fields["S"])
+		spaced := strings.Replace(string(b), ",", ", ", -1)
+
+		for _, m := range []string{reordered, spaced} {
+			decoded := &Signature{}
+			assert.NoError(t, decoded.Unmarshal([]byte(m)))
+			assert.True(t, decoded.Verify(message))
+
+			err := decoded.UnmarshalWithOptions([]byte(m), strict)
+			assert.Equal(t, ErrNonCanonicalSignature, err)
+		}
+	})
+
+	t.Run("Rejects non-canonical base64", func(t *testing.T) {
+		// The last character carries padding bits that decoders ignore.
+		last := strings.IndexByte(base64Alphabet, encoded[len(strings.TrimRight(encoded, "="))-1])
+		trimmed := strings.TrimRight(encoded, "=")
+		padding := encoded[len(trimmed):]
+		if len(padding) == 0 {
+			t.Skip("no padding bits in this encoding")
+		}
+
+		altered := trimmed[:len(trimmed)-1] + string(base64Alphabet[last|1]) + padding
+		if altered == encoded {
+			altered = trimmed[:len(trimmed)-1] + string(base64Alphabet[last&^1]) + padding
+		}
+
+		decoded := &Signature{}
+		assert.NoError(t, decoded.Decode(altered))
+		assert.True(t, decoded.Verify(message))
+
+		err := decoded.DecodeWithOptions(altered, strict)
+		assert.Equal(t, ErrNonCanonicalSignature, err)
+	})
+}
+
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+func FuzzDecode(f *testing.F) {
+	pubKeys, privKeys := GenerateKeys(3)
+	message := []byte("fuzz me")
+
+	sig, err := privKeys[0].Sign(nil, message, pubKeys, 0)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	encoded, err := sig.Encode()
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(encoded)
+	f.Add("")
+	f.Add(base64.StdEncoding.EncodeToString([]byte("{\"R\":[\"AA==\",\"BA==\"],\"S\":[\"AA==\",\"\"],\"E\":\"AA==\"}")))
+
+	f.Fuzz(func(t *testing.T, data string) {
+		decoded := &Signature{}
+		if err := decoded.Decode(data); err != nil {
+			return
+		}
+
+		decoded.Verify(message)
+	})
+}
+
+func FuzzDecodeLinkable(f *testing.F) {
+	pubKeys, privKeys := GenerateKeys(3)
+	message := []byte("fuzz me")
+
+	sig, err := privKeys[1].SignLinkable(nil, message, pubKeys, 1, []byte("scope"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	encoded, err := sig.Encode()
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(encoded)
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		decoded := &LinkableSignature{}
+		if err := decoded.Decode(data); err != nil {
+			return
+		}
+
+		decoded.Verify(message)
+	})
+}
+
+func FuzzDecodeAllTypes(f *testing.F) {
+	message := []byte("fuzz me")
+	samples := encodedSamples(f, message)
+	for name, sample := range samples {
+		f.Add(name, sample.encoded)
+	}
+
+	f.Fuzz(func(t *testing.T, name string, data string) {
+		sample, ok := samples[name]
+		if !ok {
+			return
+		}
+
+		decoded := sample.decoder()
+		if err := decoded.DecodeWithOptions(data, DefaultDecodeOptions); err != nil {
+			return
+		}
+
+		if sig, ok := decoded.(interface{ Verify([]byte) bool }); ok {
+			sig.Verify(message)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package ring
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrKeyExpired is returned when a ring contains a key that expired
+	// before the signature was produced.
+	ErrKeyExpired = errors.New("the ring contains an expired key")
+
+	// ErrKeyNotYetValid is returned when a ring contains a key whose
+	// validity period hadn't started when the signature was produced.
+	ErrKeyNotYetValid = errors.New("the ring contains a key that is not yet valid")
+)
+
+// KeyDescriptor is a public key with its validity period.
+// Times are inclusive; zero values leave the period unbounded.
+type KeyDescriptor struct {
+	PublicKey
+	// NotBefore is the time from which the key can be used.
+	NotBefore time.Time
+	// NotAfter is the time after which the key is expired.
+	NotAfter time.Time
+}
+
+// CheckValidity returns an error if the key isn't valid at the given time.
+func (d KeyDescriptor) CheckValidity(at time.Time) error {
+	if !d.NotBefore.IsZero() && at.Before(d.NotBefore) {
+		return ErrKeyNotYetValid
+	}
+
+	if !d.NotAfter.IsZero() && at.After(d.NotAfter) {
+		return ErrKeyExpired
+	}
+
+	return nil
+}
+
+// CheckRingValidity returns an error if one of the ring keys isn't valid at
+// the given time according to its descriptor.
+// Keys without a descriptor are considered valid.
+func CheckRingValidity(ringKeys []PublicKey, descriptors []KeyDescriptor, at time.Time) error {
+	for i, pk := range ringKeys {
+		for _, d := range descriptors {
+			if !bytes.Equal(d.PublicKey, pk) {
+				continue
+			}
+
+			if err := d.CheckValidity(at); err != nil {
+				f, _ := pk.Fingerprint()
+				return errors.Wrapf(err, "ring member %d (%s)", i, f)
+			}
+		}
+	}
+
+	return nil
+}
+
+// VerifyWithDescriptors verifies the validity of the message signature and
+// rejects it if a ring member wasn't valid when it was produced.
+//
+// The signature is dated with its signed timestamp, or with the current
+// time if it has none (see VerifyWithRevocations). As the timestamp is
+// chosen by the signer, it only catches honest signers using outdated rings:
+// it doesn't prevent the owner of an expired key from backdating a signature.
+func (sig *Signature) VerifyWithDescriptors(message []byte, descriptors []KeyDescriptor) error {
+	if !sig.Verify(message) {
+		return ErrInvalidSignature
+	}
+
+	return CheckRingValidity(sig.ring, descriptors, sig.signedAt())
+}
+
+// signedAt returns the signed timestamp of the signature.
+// Undated signatures are considered produced at the current time.
+func (sig *Signature) signedAt() time.Time {
+	if sig.attrs != nil && !sig.attrs.Timestamp.IsZero() {
+		return sig.attrs.Timestamp
+	}
+
+	return time.Now()
+}
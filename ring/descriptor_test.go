@@ -0,0 +1,67 @@
+package ring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyDescriptor(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+	carolPub, _ := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub, carolPub}
+
+	issued := time.Unix(1538000000, 0).UTC()
+	expiry := issued.AddDate(1, 0, 0)
+	descriptors := []KeyDescriptor{
+		{PublicKey: alicePub},
+		{PublicKey: bobPub, NotBefore: issued, NotAfter: expiry},
+	}
+
+	message := []byte("rotated yearly")
+
+	t.Run("Checks the validity period", func(t *testing.T) {
+		d := descriptors[1]
+		assert.NoError(t, d.CheckValidity(issued))
+		assert.NoError(t, d.CheckValidity(expiry))
+		assert.Equal(t, ErrKeyNotYetValid, d.CheckValidity(issued.Add(-time.Second)))
+		assert.Equal(t, ErrKeyExpired, d.CheckValidity(expiry.Add(time.Second)))
+		assert.NoError(t, descriptors[0].CheckValidity(time.Time{}))
+	})
+
+	t.Run("Checks rings", func(t *testing.T) {
+		assert.NoError(t, CheckRingValidity(ringKeys, descriptors, issued.Add(time.Hour)))
+		assert.NoError(t, CheckRingValidity(ringKeys, nil, expiry.Add(time.Hour)))
+		assert.NoError(t, CheckRingValidity([]PublicKey{alicePub, carolPub}, descriptors, expiry.Add(time.Hour)))
+
+		err := CheckRingValidity(ringKeys, descriptors, expiry.Add(time.Hour))
+		assert.Equal(t, ErrKeyExpired, errors.Cause(err))
+	})
+
+	t.Run("Verifies signatures at their timestamp", func(t *testing.T) {
+		sig, err := alicePriv.SignWithAttributes(nil, message, ringKeys, 0, Attributes{Timestamp: issued.Add(time.Hour)})
+		assert.NoError(t, err, "SignWithAttributes()")
+		assert.NoError(t, sig.VerifyWithDescriptors(message, descriptors))
+		assert.Equal(t, ErrInvalidSignature, sig.VerifyWithDescriptors([]byte("not rotated"), descriptors))
+
+		sig, err = alicePriv.SignWithAttributes(nil, message, ringKeys, 0, Attributes{Timestamp: expiry.Add(time.Hour)})
+		assert.NoError(t, err, "SignWithAttributes()")
+		assert.Equal(t, ErrKeyExpired, errors.Cause(sig.VerifyWithDescriptors(message, descriptors)))
+
+		sig, err = alicePriv.SignWithAttributes(nil, message, ringKeys, 0, Attributes{Timestamp: issued.Add(-time.Hour)})
+		assert.NoError(t, err, "SignWithAttributes()")
+		assert.Equal(t, ErrKeyNotYetValid, errors.Cause(sig.VerifyWithDescriptors(message, descriptors)))
+	})
+
+	t.Run("Verifies undated signatures at the current time", func(t *testing.T) {
+		sig, err := alicePriv.Sign(nil, message, ringKeys, 0)
+		assert.NoError(t, err, "Sign()")
+		assert.Equal(t, ErrKeyExpired, errors.Cause(sig.VerifyWithDescriptors(message, descriptors)))
+
+		current := []KeyDescriptor{{PublicKey: bobPub, NotBefore: issued, NotAfter: time.Now().Add(time.Hour)}}
+		assert.NoError(t, sig.VerifyWithDescriptors(message, current))
+	})
+}
@@ -0,0 +1,82 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidPublicKey is returned when a public key isn't a valid curve point.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+
+	// ErrInvalidFingerprint is returned when parsing a malformed fingerprint.
+	ErrInvalidFingerprint = errors.New("invalid fingerprint")
+)
+
+// keyGroupID identifies the group public keys belong to.
+const keyGroupID = "P-384"
+
+// shortIDSize is the number of fingerprint bytes used in short IDs.
+const shortIDSize = 8
+
+// Fingerprint is a digest identifying a public key.
+// It is computed as SHA256(group ID || 0x00 || compressed point).
+type Fingerprint [sha256.Size]byte
+
+// Fingerprint computes the fingerprint of the public key.
+func (pk PublicKey) Fingerprint() (Fingerprint, error) {
+	curve := elliptic.P384()
+	x, y := elliptic.Unmarshal(curve, pk)
+	if x == nil {
+		return Fingerprint{}, ErrInvalidPublicKey
+	}
+
+	h := sha256.New()
+	h.Write([]byte(keyGroupID))
+	h.Write([]byte{0x00})
+	h.Write(elliptic.MarshalCompressed(curve, x, y))
+
+	var f Fingerprint
+	copy(f[:], h.Sum(nil))
+	return f, nil
+}
+
+// String formats the fingerprint in groups of four hex characters,
+// which is easier to compare by eye.
+func (f Fingerprint) String() string {
+	encoded := hex.EncodeToString(f[:])
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// ShortID returns a short identifier for the key derived from its fingerprint.
+func (f Fingerprint) ShortID() string {
+	return hex.EncodeToString(f[:shortIDSize])
+}
+
+// HasPrefix returns true if the fingerprint starts with the given prefix.
+// The prefix is case-insensitive and can contain spaces or colons.
+func (f Fingerprint) HasPrefix(prefix string) bool {
+	prefix = strings.ToLower(strings.NewReplacer(" ", "", ":", "").Replace(prefix))
+	return len(prefix) > 0 && strings.HasPrefix(hex.EncodeToString(f[:]), prefix)
+}
+
+// ParseFingerprint parses a fingerprint formatted by String or as plain hex.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	var f Fingerprint
+	b, err := hex.DecodeString(strings.NewReplacer(" ", "", ":", "").Replace(s))
+	if err != nil || len(b) != len(f) {
+		return f, ErrInvalidFingerprint
+	}
+
+	copy(f[:], b)
+	return f, nil
+}
@@ -0,0 +1,67 @@
+package ring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+
+	t.Run("Is deterministic", func(t *testing.T) {
+		f1, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		f2, err := alicePriv.Public().Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+		assert.Equal(t, f1, f2)
+
+		f3, err := bobPub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+		assert.NotEqual(t, f1, f3)
+	})
+
+	t.Run("Rejects invalid keys", func(t *testing.T) {
+		_, err := PublicKey("not a key").Fingerprint()
+		assert.Equal(t, ErrInvalidPublicKey, err)
+	})
+
+	t.Run("Formats fingerprint", func(t *testing.T) {
+		f, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		assert.Len(t, strings.Fields(f.String()), 16)
+		assert.Len(t, f.ShortID(), 16)
+		assert.True(t, strings.HasPrefix(strings.Replace(f.String(), " ", "", -1), f.ShortID()))
+	})
+
+	t.Run("Matches prefixes", func(t *testing.T) {
+		f, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		assert.True(t, f.HasPrefix(f.ShortID()))
+		assert.True(t, f.HasPrefix(strings.ToUpper(f.String()[:9])))
+		assert.True(t, f.HasPrefix(f.String()))
+		assert.False(t, f.HasPrefix(""))
+		assert.False(t, f.HasPrefix(f.ShortID()+"zz"))
+	})
+
+	t.Run("Parses formatted fingerprints", func(t *testing.T) {
+		f, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		parsed, err := ParseFingerprint(f.String())
+		assert.NoError(t, err, "ParseFingerprint()")
+		assert.Equal(t, f, parsed)
+
+		parsed, err = ParseFingerprint(strings.Replace(f.String(), " ", "", -1))
+		assert.NoError(t, err, "ParseFingerprint()")
+		assert.Equal(t, f, parsed)
+
+		_, err = ParseFingerprint(f.ShortID())
+		assert.Equal(t, ErrInvalidFingerprint, err)
+	})
+}
@@ -0,0 +1,308 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidMnemonic is returned when a mnemonic phrase is malformed
+	// or its checksum doesn't match.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic phrase")
+
+	// ErrInvalidSeed is returned when a seed is too short or too long.
+	ErrInvalidSeed = errors.New("the seed should be between 16 and 64 bytes")
+
+	// ErrInvalidPath is returned when a derivation path is malformed.
+	ErrInvalidPath = errors.New("invalid derivation path")
+
+	// ErrInvalidChild is returned in the (very unlikely) case where a
+	// child index produces an invalid key. The next index should be used.
+	ErrInvalidChild = errors.New("the child index produces an invalid key: use the next one")
+)
+
+const (
+	// HardenedKeyStart is the first index of hardened child keys.
+	HardenedKeyStart uint32 = 0x80000000
+
+	// DefaultDerivationPath is the path of the first ring identity.
+	DefaultDerivationPath = "m/0'"
+
+	// mnemonicEntropySize is the size of the entropy encoded by new
+	// mnemonics (24 words).
+	mnemonicEntropySize = 32
+
+	masterKeySalt = "ring-signatures P-384 seed"
+)
+
+// NewMnemonic generates a random mnemonic phrase (see BIP39).
+// If no random generator is provided, NewMnemonic will use
+// go's default cryptographic random generator.
+func NewMnemonic(rand io.Reader) (string, error) {
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	entropy := make([]byte, mnemonicEntropySize)
+	_, err := io.ReadFull(rand, entropy)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic encodes entropy as a mnemonic phrase (see BIP39).
+// The entropy should be 16, 20, 24, 28 or 32 bytes long.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", errors.New("the entropy should be 16, 20, 24, 28 or 32 bytes")
+	}
+
+	// The checksum is the first len(entropy)/4 bits of its hash.
+	checksumBits := uint(len(entropy) / 4)
+	h := sha256.Sum256(entropy)
+	checksum := big.NewInt(int64(h[0] >> (8 - checksumBits)))
+
+	bits := new(big.Int).SetBytes(entropy)
+	bits.Lsh(bits, checksumBits)
+	bits.Or(bits, checksum)
+
+	// Each word encodes 11 bits.
+	wordCount := (len(entropy)*8 + int(checksumBits)) / 11
+	words := make([]string, wordCount)
+	mask := big.NewInt(2047)
+	for i := wordCount - 1; i >= 0; i-- {
+		words[i] = mnemonicWords[new(big.Int).And(bits, mask).Int64()]
+		bits.Rsh(bits, 11)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes a mnemonic phrase and verifies its checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidMnemonic
+	}
+
+	bits := new(big.Int)
+	for _, w := range words {
+		i, ok := mnemonicIndex(w)
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidMnemonic, "unknown word %s", w)
+		}
+
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(i)))
+	}
+
+	checksumBits := uint(len(words) / 3)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1))
+	bits.Rsh(bits, checksumBits)
+
+	entropy := bits.FillBytes(make([]byte, len(words)*4/3))
+	h := sha256.Sum256(entropy)
+	if int64(h[0]>>(8-checksumBits)) != checksum.Int64() {
+		return nil, errors.Wrap(ErrInvalidMnemonic, "invalid checksum")
+	}
+
+	return entropy, nil
+}
+
+// mnemonicIndex finds a word in the (sorted) word list.
+func mnemonicIndex(word string) (int, bool) {
+	lo, hi := 0, len(mnemonicWords)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case mnemonicWords[mid] == word:
+			return mid, true
+		case mnemonicWords[mid] < word:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, false
+}
+
+// MnemonicToSeed verifies a mnemonic phrase and turns it into a seed
+// that can be used to create a master key (see BIP39).
+// The passphrase is optional and protects the seed in case the mnemonic
+// is stolen. It isn't unicode-normalized, so non-ASCII passphrases should
+// be normalized (NFKD) by the caller to stay compatible with BIP39.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	_, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	password := strings.Join(strings.Fields(mnemonic), " ")
+	salt := "mnemonic" + passphrase
+
+	return pbkdf2([]byte(password), []byte(salt), 2048, 64), nil
+}
+
+// pbkdf2 derives a key from a password with HMAC-SHA512 (see RFC 8018).
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha512.New, password)
+	var dk []byte
+	for block := uint32(1); len(dk) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for n := 1; n < iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range t {
+				t[i] ^= u[i]
+			}
+		}
+
+		dk = append(dk, t...)
+	}
+
+	return dk[:keyLen]
+}
+
+// HDKey is a hierarchical deterministic private key: it can be used to
+// derive a tree of independent ring identities from a single seed.
+type HDKey struct {
+	PrivateKey PrivateKey
+	ChainCode  []byte
+}
+
+// Key derivation (BIP32 adapted to P-384):
+//	* Let c be the chain code and k the private key of the parent
+//	* For hardened children (i >= 2^31), data = 0x00 || k || i
+//	* For normal children, data = compressed(k*G) || i
+//	* I = HMAC-SHA512(c, 0x01 || data) || HMAC-SHA512(c, 0x02 || data)
+//	* The child key is (I[0:64] + k) mod N and its chain code is I[64:96]
+//	* The master key is derived the same way from the seed with k = 0
+//
+// The first 64 bytes of I are reduced modulo N so that the child
+// key is uniformly distributed even though N is a 384 bits number.
+
+// NewMasterKey creates the root of a key tree from a seed.
+func NewMasterKey(seed []byte) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ErrInvalidSeed
+	}
+
+	return deriveKey([]byte(masterKeySalt), seed, nil)
+}
+
+// Child derives the child key at the given index.
+// Indexes greater than HardenedKeyStart produce hardened keys.
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	curve := elliptic.P384()
+
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0x00}, new(big.Int).SetBytes(k.PrivateKey).FillBytes(make([]byte, coordinateSize(curve)))...)
+	} else {
+		x, y := curve.ScalarBaseMult(k.PrivateKey)
+		data = elliptic.MarshalCompressed(curve, x, y)
+	}
+
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+	return deriveKey(k.ChainCode, data, k.PrivateKey)
+}
+
+// Derive derives the key at the given path (for example "m/0'/1'").
+func (k *HDKey) Derive(path string) (*HDKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	child := k
+	for _, i := range indexes {
+		child, err = child.Child(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return child, nil
+}
+
+// PublicKey returns the public key of the ring identity.
+func (k *HDKey) PublicKey() PublicKey {
+	return k.PrivateKey.Public()
+}
+
+// deriveKey derives a key from a parent key and chain code.
+func deriveKey(chainCode, data []byte, parent PrivateKey) (*HDKey, error) {
+	curve := elliptic.P384()
+
+	var i []byte
+	for _, prefix := range []byte{0x01, 0x02} {
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write([]byte{prefix})
+		mac.Write(data)
+		i = mac.Sum(i)
+	}
+
+	child := new(big.Int).SetBytes(i[:64])
+	child.Add(child, new(big.Int).SetBytes(parent))
+	child.Mod(child, curve.Params().N)
+	if child.Sign() == 0 {
+		return nil, ErrInvalidChild
+	}
+
+	return &HDKey{
+		PrivateKey: PrivateKey(child.FillBytes(make([]byte, coordinateSize(curve)))),
+		ChainCode:  i[64:96],
+	}, nil
+}
+
+// ParseDerivationPath parses a derivation path such as "m/0'/1".
+// Hardened indexes are marked with a trailing ' or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] != "m" {
+		return nil, errors.Wrap(ErrInvalidPath, "paths should start with m")
+	}
+
+	var indexes []uint32
+	for _, p := range parts[1:] {
+		hardened := strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h")
+		if hardened {
+			p = p[:len(p)-1]
+		}
+
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(i) >= HardenedKeyStart {
+			return nil, errors.Wrapf(ErrInvalidPath, "invalid index %s", p)
+		}
+
+		if hardened {
+			i += uint64(HardenedKeyStart)
+		}
+
+		indexes = append(indexes, uint32(i))
+	}
+
+	return indexes, nil
+}
@@ -0,0 +1,133 @@
+package ring
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMnemonic(t *testing.T) {
+	t.Run("Word list is sorted", func(t *testing.T) {
+		assert.Len(t, mnemonicWords, 2048)
+		for i := 1; i < len(mnemonicWords); i++ {
+			assert.True(t, mnemonicWords[i-1] < mnemonicWords[i], mnemonicWords[i])
+		}
+	})
+
+	t.Run("Matches BIP39 test vectors", func(t *testing.T) {
+		vectors := []struct {
+			entropy  string
+			mnemonic string
+			seed     string
+		}{{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		}, {
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+		}}
+
+		for _, v := range vectors {
+			entropy, _ := hex.DecodeString(v.entropy)
+			mnemonic, err := EntropyToMnemonic(entropy)
+			assert.NoError(t, err, "EntropyToMnemonic()")
+			assert.Equal(t, v.mnemonic, mnemonic)
+
+			decoded, err := MnemonicToEntropy(mnemonic)
+			assert.NoError(t, err, "MnemonicToEntropy()")
+			assert.Equal(t, entropy, decoded)
+
+			seed, err := MnemonicToSeed(mnemonic, "TREZOR")
+			assert.NoError(t, err, "MnemonicToSeed()")
+			assert.Equal(t, v.seed, hex.EncodeToString(seed))
+		}
+	})
+
+	t.Run("Generates valid mnemonics", func(t *testing.T) {
+		mnemonic, err := NewMnemonic(nil)
+		assert.NoError(t, err, "NewMnemonic()")
+		assert.Len(t, strings.Fields(mnemonic), 24)
+
+		_, err = MnemonicToEntropy(mnemonic)
+		assert.NoError(t, err, "MnemonicToEntropy()")
+	})
+
+	t.Run("Rejects invalid mnemonics", func(t *testing.T) {
+		_, err := MnemonicToEntropy("abandon abandon abandon")
+		assert.Equal(t, ErrInvalidMnemonic, errors.Cause(err))
+
+		_, err = MnemonicToEntropy(strings.Repeat("abandon ", 12))
+		assert.Equal(t, ErrInvalidMnemonic, errors.Cause(err))
+
+		_, err = MnemonicToEntropy(strings.Repeat("satoshi ", 12))
+		assert.Equal(t, ErrInvalidMnemonic, errors.Cause(err))
+	})
+}
+
+func TestHDKey(t *testing.T) {
+	seed, err := MnemonicToSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	assert.NoError(t, err, "MnemonicToSeed()")
+
+	master, err := NewMasterKey(seed)
+	assert.NoError(t, err, "NewMasterKey()")
+
+	t.Run("Rejects invalid seeds", func(t *testing.T) {
+		_, err := NewMasterKey(make([]byte, 8))
+		assert.Equal(t, ErrInvalidSeed, err)
+	})
+
+	t.Run("Parses derivation paths", func(t *testing.T) {
+		indexes, err := ParseDerivationPath("m/0'/1/2h")
+		assert.NoError(t, err, "ParseDerivationPath()")
+		assert.Equal(t, []uint32{HardenedKeyStart, 1, HardenedKeyStart + 2}, indexes)
+
+		indexes, err = ParseDerivationPath("m")
+		assert.NoError(t, err, "ParseDerivationPath()")
+		assert.Empty(t, indexes)
+
+		for _, path := range []string{"", "0/1", "m/", "m/-1", "m/2147483648", "m/a'"} {
+			_, err := ParseDerivationPath(path)
+			assert.Equal(t, ErrInvalidPath, errors.Cause(err), path)
+		}
+	})
+
+	t.Run("Derives deterministic keys", func(t *testing.T) {
+		k1, err := master.Derive("m/0'/1")
+		assert.NoError(t, err, "Derive()")
+
+		master2, err := NewMasterKey(seed)
+		assert.NoError(t, err, "NewMasterKey()")
+
+		k2, err := master2.Derive("m/0'/1")
+		assert.NoError(t, err, "Derive()")
+		assert.Equal(t, k1, k2)
+		assert.Len(t, k1.PrivateKey, 48)
+		assert.Len(t, k1.ChainCode, 32)
+	})
+
+	t.Run("Derives independent keys", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for _, path := range []string{"m", "m/0", "m/0'", "m/1'", "m/0'/0'", "m/0'/0"} {
+			k, err := master.Derive(path)
+			assert.NoError(t, err, "Derive()")
+			assert.False(t, seen[string(k.PrivateKey)], path)
+			seen[string(k.PrivateKey)] = true
+		}
+	})
+
+	t.Run("Derived keys sign", func(t *testing.T) {
+		alice, err := master.Derive(DefaultDerivationPath)
+		assert.NoError(t, err, "Derive()")
+
+		bobPub, _ := Generate(nil)
+		message := []byte("one seed to rule them all")
+		sig, err := alice.PrivateKey.Sign(nil, message, []PublicKey{bobPub, alice.PublicKey()}, 1)
+		assert.NoError(t, err, "Sign()")
+		assert.True(t, sig.Verify(message))
+	})
+}
@@ -0,0 +1,216 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnsupportedKeyType is returned when a JSON Web Key uses a key type
+	// or curve that can't be used in a ring.
+	ErrUnsupportedKeyType = errors.New("unsupported key type: only EC keys on P-384 can be used in a ring")
+
+	// ErrInvalidJWK is returned when a JSON Web Key is malformed.
+	ErrInvalidJWK = errors.New("invalid JSON Web Key")
+)
+
+// JSON Web Key parameters (see RFC 7517 and RFC 7518).
+const (
+	jwkKeyTypeEC = "EC"
+	jwkCurveP384 = "P-384"
+)
+
+// JWK is the JSON Web Key representation of a key (RFC 7517).
+// Only elliptic curve keys (kty EC) on P-384 can be used in a ring:
+// octet key pairs (kty OKP) are recognized but rejected.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517 section 5).
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// JWK returns the JSON Web Key representation of the public key.
+func (pk PublicKey) JWK() (*JWK, error) {
+	curve := elliptic.P384()
+	x, y := elliptic.Unmarshal(curve, pk)
+	if x == nil {
+		return nil, ErrInvalidJWK
+	}
+
+	size := coordinateSize(curve)
+	return &JWK{
+		Kty: jwkKeyTypeEC,
+		Crv: jwkCurveP384,
+		X:   base64.RawURLEncoding.EncodeToString(x.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(y.FillBytes(make([]byte, size))),
+	}, nil
+}
+
+// JWK returns the JSON Web Key representation of the private key.
+// It contains the public key as well, as required by RFC 7518.
+func (sk PrivateKey) JWK() (*JWK, error) {
+	curve := elliptic.P384()
+	d := new(big.Int).SetBytes(sk)
+	if d.Sign() <= 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, ErrInvalidJWK
+	}
+
+	k, err := sk.Public().JWK()
+	if err != nil {
+		return nil, err
+	}
+
+	k.D = base64.RawURLEncoding.EncodeToString(d.FillBytes(make([]byte, coordinateSize(curve))))
+
+	return k, nil
+}
+
+// PublicKey extracts the public key from a JSON Web Key.
+// The point is checked to be on the curve.
+func (k *JWK) PublicKey() (PublicKey, error) {
+	curve, err := k.curve()
+	if err != nil {
+		return nil, err
+	}
+
+	size := coordinateSize(curve)
+	x, err := decodeJWKParam(k.X, size)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeJWKParam(k.Y, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.Wrap(ErrInvalidJWK, "point is not on the curve")
+	}
+
+	return PublicKey(elliptic.Marshal(curve, x, y)), nil
+}
+
+// PrivateKey extracts the private key from a JSON Web Key.
+// The public coordinates must match the private scalar.
+func (k *JWK) PrivateKey() (PrivateKey, error) {
+	curve, err := k.curve()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(k.D) == 0 {
+		return nil, errors.Wrap(ErrInvalidJWK, "missing private key")
+	}
+
+	d, err := decodeJWKParam(k.D, coordinateSize(curve))
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errors.Wrap(ErrInvalidJWK, "private key is out of range")
+	}
+
+	pk, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sk := PrivateKey(d.FillBytes(make([]byte, coordinateSize(curve))))
+	if !bytes.Equal(sk.Public(), pk) {
+		return nil, errors.Wrap(ErrInvalidJWK, "private key does not match public key")
+	}
+
+	return sk, nil
+}
+
+// curve returns the curve used by the JSON Web Key.
+func (k *JWK) curve() (elliptic.Curve, error) {
+	switch k.Kty {
+	case jwkKeyTypeEC:
+		if k.Crv != jwkCurveP384 {
+			return nil, ErrUnsupportedKeyType
+		}
+
+		return elliptic.P384(), nil
+	default:
+		// OKP keys (Ed25519, X25519) use curves the ring scheme
+		// doesn't support.
+		return nil, ErrUnsupportedKeyType
+	}
+}
+
+// decodeJWKParam decodes a base64url-encoded curve parameter.
+// RFC 7518 requires parameters to use the full coordinate size.
+func decodeJWKParam(param string, size int) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(param)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidJWK, err.Error())
+	}
+
+	if len(b) != size {
+		return nil, errors.Wrap(ErrInvalidJWK, "invalid parameter length")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// coordinateSize returns the byte length of a curve coordinate.
+func coordinateSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// RingToJWKS encodes a ring of public keys as a JSON Web Key Set.
+func RingToJWKS(ringKeys []PublicKey) ([]byte, error) {
+	set := &JWKS{Keys: make([]*JWK, len(ringKeys))}
+	for i, pk := range ringKeys {
+		k, err := pk.JWK()
+		if err != nil {
+			return nil, err
+		}
+
+		set.Keys[i] = k
+	}
+
+	return json.Marshal(set)
+}
+
+// RingFromJWKS decodes a ring of public keys from a JSON Web Key Set.
+// The keys are returned in the order in which they appear in the set.
+func RingFromJWKS(data []byte) ([]PublicKey, error) {
+	set := &JWKS{}
+	err := json.Unmarshal(data, set)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidJWK, err.Error())
+	}
+
+	ringKeys := make([]PublicKey, len(set.Keys))
+	for i, k := range set.Keys {
+		if k == nil {
+			return nil, ErrInvalidJWK
+		}
+
+		pk, err := k.PublicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %d", i)
+		}
+
+		ringKeys[i] = pk
+	}
+
+	return ringKeys, nil
+}
@@ -0,0 +1,114 @@
+package ring
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWK(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+
+	t.Run("Derives public key", func(t *testing.T) {
+		assert.EqualValues(t, alicePub, alicePriv.Public())
+	})
+
+	t.Run("Encodes and decodes public key", func(t *testing.T) {
+		k, err := alicePub.JWK()
+		assert.NoError(t, err, "JWK()")
+		assert.Equal(t, "EC", k.Kty)
+		assert.Equal(t, "P-384", k.Crv)
+		assert.Empty(t, k.D)
+
+		decoded, err := k.PublicKey()
+		assert.NoError(t, err, "PublicKey()")
+		assert.EqualValues(t, alicePub, decoded)
+
+		_, err = k.PrivateKey()
+		assert.Error(t, err)
+	})
+
+	t.Run("Encodes and decodes private key", func(t *testing.T) {
+		k, err := alicePriv.JWK()
+		assert.NoError(t, err, "JWK()")
+		assert.NotEmpty(t, k.D)
+
+		decoded, err := k.PrivateKey()
+		assert.NoError(t, err, "PrivateKey()")
+		assert.EqualValues(t, alicePriv, decoded)
+	})
+
+	t.Run("Rejects mismatched private key", func(t *testing.T) {
+		k, err := alicePriv.JWK()
+		assert.NoError(t, err, "JWK()")
+
+		bobKey, err := bobPub.JWK()
+		assert.NoError(t, err, "JWK()")
+
+		k.X, k.Y = bobKey.X, bobKey.Y
+		_, err = k.PrivateKey()
+		assert.Equal(t, ErrInvalidJWK, errors.Cause(err))
+	})
+
+	t.Run("Rejects unsupported keys", func(t *testing.T) {
+		_, err := (&JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}).PublicKey()
+		assert.Equal(t, ErrUnsupportedKeyType, err)
+
+		k, err := alicePub.JWK()
+		assert.NoError(t, err, "JWK()")
+
+		k.Crv = "P-256"
+		_, err = k.PublicKey()
+		assert.Equal(t, ErrUnsupportedKeyType, err)
+	})
+
+	t.Run("Rejects points not on the curve", func(t *testing.T) {
+		k, err := alicePub.JWK()
+		assert.NoError(t, err, "JWK()")
+
+		k.X, k.Y = k.Y, k.X
+		_, err = k.PublicKey()
+		assert.Equal(t, ErrInvalidJWK, errors.Cause(err))
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+	carolPub, _ := Generate(nil)
+
+	t.Run("Encodes and decodes ring", func(t *testing.T) {
+		ringKeys := []PublicKey{alicePub, bobPub, carolPub}
+		b, err := RingToJWKS(ringKeys)
+		assert.NoError(t, err, "RingToJWKS()")
+
+		decoded, err := RingFromJWKS(b)
+		assert.NoError(t, err, "RingFromJWKS()")
+		assert.EqualValues(t, ringKeys, decoded)
+
+		message := []byte("members only")
+		sig, err := alicePriv.Sign(nil, message, decoded, 0)
+		assert.NoError(t, err, "Sign()")
+		assert.True(t, sig.Verify(message))
+	})
+
+	t.Run("Rejects invalid keys", func(t *testing.T) {
+		k, err := alicePub.JWK()
+		assert.NoError(t, err, "JWK()")
+
+		k.Crv = "P-521"
+		b, err := json.Marshal(&JWKS{Keys: []*JWK{k}})
+		assert.NoError(t, err)
+
+		_, err = RingFromJWKS(b)
+		assert.Equal(t, ErrUnsupportedKeyType, errors.Cause(err))
+	})
+
+	t.Run("Rejects invalid documents", func(t *testing.T) {
+		_, err := RingFromJWKS([]byte("{\"keys\": 42}"))
+		assert.Equal(t, ErrInvalidJWK, errors.Cause(err))
+	})
+}
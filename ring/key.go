@@ -1,6 +1,7 @@
 package ring
 
 import (
+	"crypto/elliptic"
 	"encoding/base64"
 )
 
@@ -10,6 +11,13 @@ type PublicKey []byte
 // PrivateKey defines a private key in assymetric encryption.
 type PrivateKey []byte
 
+// Public returns the public key matching the private key.
+func (sk PrivateKey) Public() PublicKey {
+	curve := elliptic.P384()
+	x, y := curve.ScalarBaseMult(sk)
+	return PublicKey(elliptic.Marshal(curve, x, y))
+}
+
 // ConfigEncodeKey encodes a key to a friendly string format
 // that can be stored in configuration files.
 func ConfigEncodeKey(key []byte) string {
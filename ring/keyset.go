@@ -0,0 +1,85 @@
+package ring
+
+// KeySet is a set of trusted public keys.
+// The zero value is an empty set ready to use.
+type KeySet struct {
+	keys map[string]struct{}
+}
+
+// NewKeySet creates a set containing the given keys.
+func NewKeySet(keys ...PublicKey) KeySet {
+	var s KeySet
+	for _, pk := range keys {
+		s.Add(pk)
+	}
+
+	return s
+}
+
+// Add adds a key to the set.
+func (s *KeySet) Add(pk PublicKey) {
+	if s.keys == nil {
+		s.keys = make(map[string]struct{})
+	}
+
+	s.keys[string(pk)] = struct{}{}
+}
+
+// Contains returns true if the key is in the set.
+func (s KeySet) Contains(pk PublicKey) bool {
+	_, ok := s.keys[string(pk)]
+	return ok
+}
+
+// Len returns the number of keys in the set.
+func (s KeySet) Len() int {
+	return len(s.keys)
+}
+
+// Equal returns true if both sets contain the same keys.
+func (s KeySet) Equal(other KeySet) bool {
+	if s.Len() != other.Len() {
+		return false
+	}
+
+	for k := range s.keys {
+		if _, ok := other.keys[k]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// VerifyWithRing verifies the validity of the message signature and
+// requires the signature's ring to contain exactly the expected keys.
+// The order of the keys doesn't matter.
+func (sig *Signature) VerifyWithRing(message []byte, expected []PublicKey) bool {
+	if sig == nil {
+		return false
+	}
+
+	if !NewKeySet(sig.ring...).Equal(NewKeySet(expected...)) {
+		return false
+	}
+
+	return sig.Verify(message)
+}
+
+// VerifySubsetOf verifies the validity of the message signature and
+// requires every member of the signature's ring to belong to the allowed keys.
+// Without that check, anyone can produce a valid signature with a ring of
+// their own keys.
+func (sig *Signature) VerifySubsetOf(message []byte, allowed KeySet) bool {
+	if sig == nil {
+		return false
+	}
+
+	for _, pk := range sig.ring {
+		if !allowed.Contains(pk) {
+			return false
+		}
+	}
+
+	return sig.Verify(message)
+}
@@ -0,0 +1,68 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySet(t *testing.T) {
+	alicePub, _ := Generate(nil)
+	bobPub, _ := Generate(nil)
+
+	var s KeySet
+	assert.False(t, s.Contains(alicePub))
+	assert.Equal(t, 0, s.Len())
+
+	s.Add(alicePub)
+	s.Add(alicePub)
+	assert.True(t, s.Contains(alicePub))
+	assert.False(t, s.Contains(bobPub))
+	assert.Equal(t, 1, s.Len())
+
+	s = NewKeySet(alicePub, bobPub)
+	assert.True(t, s.Contains(bobPub))
+	assert.Equal(t, 2, s.Len())
+
+	assert.True(t, s.Equal(NewKeySet(bobPub, alicePub, bobPub)))
+	assert.False(t, s.Equal(NewKeySet(alicePub)))
+	assert.False(t, NewKeySet(alicePub).Equal(NewKeySet(bobPub)))
+}
+
+func TestVerifyTrusted(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+	carolPub, _ := Generate(nil)
+	evePub, evePriv := Generate(nil)
+
+	message := []byte("trust no one")
+	sig, err := alicePriv.Sign(nil, message, []PublicKey{alicePub, bobPub}, 0)
+	assert.NoError(t, err, "Sign()")
+
+	forged, err := evePriv.Sign(nil, message, []PublicKey{evePub, bobPub}, 0)
+	assert.NoError(t, err, "Sign()")
+	assert.True(t, forged.Verify(message))
+
+	t.Run("Verifies with expected ring", func(t *testing.T) {
+		assert.True(t, sig.VerifyWithRing(message, []PublicKey{alicePub, bobPub}))
+		assert.True(t, sig.VerifyWithRing(message, []PublicKey{bobPub, alicePub}))
+		assert.False(t, sig.VerifyWithRing(message, []PublicKey{alicePub, bobPub, carolPub}))
+		assert.False(t, sig.VerifyWithRing(message, []PublicKey{alicePub}))
+		assert.False(t, sig.VerifyWithRing([]byte("trust everyone"), []PublicKey{alicePub, bobPub}))
+		assert.False(t, forged.VerifyWithRing(message, []PublicKey{alicePub, bobPub}))
+	})
+
+	t.Run("Verifies subset of allowed keys", func(t *testing.T) {
+		allowed := NewKeySet(alicePub, bobPub, carolPub)
+		assert.True(t, sig.VerifySubsetOf(message, allowed))
+		assert.False(t, sig.VerifySubsetOf(message, NewKeySet(alicePub, carolPub)))
+		assert.False(t, sig.VerifySubsetOf([]byte("trust everyone"), allowed))
+		assert.False(t, forged.VerifySubsetOf(message, allowed))
+	})
+
+	t.Run("Rejects nil signature", func(t *testing.T) {
+		var empty *Signature
+		assert.False(t, empty.VerifyWithRing(message, nil))
+		assert.False(t, empty.VerifySubsetOf(message, KeySet{}))
+	})
+}
@@ -0,0 +1,695 @@
+package ring
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidPrivateKey is returned when a private key can't be used.
+var ErrInvalidPrivateKey = errors.New("invalid private key")
+
+// Lattice parameters, inspired by Dilithium (security level 2).
+// They have not been audited: the lattice scheme is experimental.
+const (
+	// latticeDomain separates lattice hashes from other hashes.
+	latticeDomain = "ring-signatures/lattice/v1"
+
+	// latticeN is the degree of the polynomial ring Z(q)[X]/(X^N+1).
+	latticeN = 256
+
+	// latticeQ is the modulus of the polynomial ring.
+	latticeQ = 8380417
+
+	// latticeK and latticeL are the dimensions of the public matrix A.
+	latticeK = 4
+	latticeL = 4
+
+	// latticeEta bounds the coefficients of private keys.
+	latticeEta = 2
+
+	// latticeTau is the number of non-zero coefficients of challenges.
+	latticeTau = 39
+
+	// latticeGamma bounds the coefficients of the masking vectors.
+	latticeGamma = 1 << 19
+
+	// latticeBeta bounds the coefficients of c*s.
+	latticeBeta = latticeTau * latticeEta
+
+	// latticeSeedSize is the size of private keys.
+	latticeSeedSize = 32
+
+	// latticePublicKeySize is the size of public keys: K polynomials with
+	// 3-byte coefficients.
+	latticePublicKeySize = latticeK * latticeN * 3
+
+	// latticeResponseSize is the size of a ring member's response: K+L
+	// polynomials with 20-bit coefficients.
+	latticeResponseSize = (latticeK + latticeL) * latticeN * 20 / 8
+)
+
+// LatticePublicKey is a public key of the lattice ring signature scheme.
+type LatticePublicKey []byte
+
+// LatticePrivateKey is a private key of the lattice ring signature scheme.
+// It is the seed from which the secret vectors are derived.
+type LatticePrivateKey []byte
+
+// LatticeSignature is an experimental post-quantum ring signature based on
+// the hardness of Module-LWE and Module-SIS.
+// It is much larger and slower than elliptic curve signatures, and its
+// implementation is not constant-time.
+type LatticeSignature struct {
+	ring []LatticePublicKey
+	c    []byte
+	z    [][]byte
+}
+
+// Lattice signing algorithm (Fiat-Shamir with aborts in an AOS ring):
+//	* Let A be a public K*L matrix of polynomials and B = [A | I]
+//	* A private key is a vector s of K+L polynomials with coefficients in
+//	  [-eta:eta] and its public key is t = B*s
+//	* Let (t(0),...,t(R-1)) be all the public keys in the ring and L their
+//	  encoding
+//	* Let C(c) map a hash c to a polynomial with tau coefficients in {-1,1}
+//	* Let r be the index of the actual signer in the ring
+//	* Randomly choose y with coefficients in [-(gamma-1):gamma-1]
+//	* Compute c(r+1 % R) = H(L || m || B*y)
+//	* for i := r+1 % R; i != r; i++%R:
+//		* Randomly choose z(i) with coefficients in [-(gamma-beta-1):gamma-beta-1]
+//		* Compute c(i+1 % R) = H(L || m || B*z(i) - C(c(i))*t(i))
+//	* Compute z(r) = y + C(c(r))*s
+//	* If a coefficient of z(r) is outside of [-(gamma-beta-1):gamma-beta-1],
+//	  restart: otherwise z(r) would leak information about s
+//	* Output signature: (t(0),...,t(R-1),c(0),z(0),...,z(R-1))
+//
+// When accepted, z(r) is uniform in the same range as the other responses,
+// which hides the signer.
+
+// GenerateLattice generates a new lattice public-private key pair.
+// If no random generator is provided, GenerateLattice will use
+// go's default cryptographic random generator.
+func GenerateLattice(rand io.Reader) (LatticePublicKey, LatticePrivateKey) {
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	sk := make(LatticePrivateKey, latticeSeedSize)
+	if _, err := io.ReadFull(rand, sk); err != nil {
+		panic(fmt.Sprintf("Could not generate keys: %s", err.Error()))
+	}
+
+	return sk.Public(), sk
+}
+
+// Public returns the public key matching the private key.
+func (sk LatticePrivateKey) Public() LatticePublicKey {
+	return LatticePublicKey(packModQ(latticeImage(sk.secret())))
+}
+
+// secret derives the secret vector from the private key.
+func (sk LatticePrivateKey) secret() []latticePoly {
+	st := newLatticeStream([]byte("secret"), sk)
+	s := make([]latticePoly, latticeK+latticeL)
+	for i := range s {
+		s[i] = st.uniform(latticeEta)
+	}
+
+	return s
+}
+
+// Sign creates a lattice ring signature for the given message.
+// The public key at the signer index must match the private key.
+func (sk LatticePrivateKey) Sign(
+	rand io.Reader,
+	message []byte,
+	ringKeys []LatticePublicKey,
+	signerIndex int,
+) (*LatticeSignature, error) {
+	if len(message) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if signerIndex < 0 || len(ringKeys) <= signerIndex {
+		return nil, ErrInvalidSignerIndex
+	}
+
+	if len(ringKeys) < 2 {
+		return nil, ErrRingTooSmall
+	}
+
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if len(sk) != latticeSeedSize {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	if !bytes.Equal(sk.Public(), ringKeys[signerIndex]) {
+		return nil, ErrSignerMismatch
+	}
+
+	ts := make([][]latticePoly, len(ringKeys))
+	for i, pk := range ringKeys {
+		t, ok := unpackModQ(pk, latticeK)
+		if !ok {
+			return nil, ErrInvalidPublicKey
+		}
+
+		ts[i] = t
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	prefix := latticePrefix(ringKeys, message)
+	s := sk.secret()
+
+	for {
+		z, c, err := latticeAttempt(rand, prefix, ts, signerIndex, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if z != nil {
+			return &LatticeSignature{
+				ring: ringKeys,
+				c:    c,
+				z:    z,
+			}, nil
+		}
+	}
+}
+
+// latticeAttempt runs the signing algorithm once.
+// It returns nil responses when the signer's response must be rejected.
+func latticeAttempt(
+	rand io.Reader,
+	prefix []byte,
+	ts [][]latticePoly,
+	signerIndex int,
+	s []latticePoly,
+) ([][]byte, []byte, error) {
+	r := len(ts)
+	cs := make([][]byte, r)
+	zs := make([][]byte, r)
+
+	// Initialize the ring.
+
+	st, err := randomLatticeStream(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	y := make([]latticePoly, latticeK+latticeL)
+	for i := range y {
+		y[i] = st.uniform(latticeGamma - 1)
+	}
+
+	cs[(signerIndex+1)%r] = latticeChallenge(prefix, packModQ(latticeImage(y)))
+
+	// Iterate over the whole ring.
+
+	for i := (signerIndex + 1) % r; i != signerIndex; i = (i + 1) % r {
+		st, err := randomLatticeStream(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		z := make([]latticePoly, latticeK+latticeL)
+		for j := range z {
+			z[j] = st.uniform(latticeGamma - latticeBeta - 1)
+		}
+
+		zs[i] = packResponse(z)
+		cs[(i+1)%r] = latticeChallenge(prefix, latticeCommitment(ts[i], cs[i], z))
+	}
+
+	// Close the ring.
+
+	c := latticeChallengePoly(cs[signerIndex])
+	z := make([]latticePoly, latticeK+latticeL)
+	for i := range z {
+		z[i] = c.mul(&s[i])
+		z[i].add(&y[i])
+		for _, v := range z[i] {
+			if v < -(latticeGamma-latticeBeta-1) || latticeGamma-latticeBeta-1 < v {
+				return nil, nil, nil
+			}
+		}
+	}
+
+	zs[signerIndex] = packResponse(z)
+
+	return zs, cs[0], nil
+}
+
+// Verify verifies the validity of the message signature.
+// It does not detail why the signature validation failed.
+func (sig *LatticeSignature) Verify(message []byte) bool {
+	if sig == nil || len(sig.ring) < 2 || len(sig.z) != len(sig.ring) || len(sig.c) != sha256.Size {
+		return false
+	}
+
+	prefix := latticePrefix(sig.ring, message)
+	c := sig.c
+	for i, pk := range sig.ring {
+		t, ok := unpackModQ(pk, latticeK)
+		if !ok {
+			return false
+		}
+
+		z, ok := unpackResponse(sig.z[i])
+		if !ok {
+			return false
+		}
+
+		c = latticeChallenge(prefix, latticeCommitment(t, c, z))
+	}
+
+	return bytes.Equal(c, sig.c)
+}
+
+// Ring returns the public keys of the ring that produced the signature.
+func (sig *LatticeSignature) Ring() []LatticePublicKey {
+	return sig.ring
+}
+
+// Members returns the public keys of the ring that produced the signature.
+func (sig *LatticeSignature) Members() [][]byte {
+	members := make([][]byte, len(sig.ring))
+	for i, pk := range sig.ring {
+		members[i] = pk
+	}
+
+	return members
+}
+
+// latticePrefix hashes the ring and the message.
+func latticePrefix(ringKeys []LatticePublicKey, message []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(latticeDomain))
+	for _, pk := range ringKeys {
+		writeLengthPrefixed(h, pk)
+	}
+
+	writeLengthPrefixed(h, message)
+	return h.Sum(nil)
+}
+
+// latticeChallenge computes the next challenge of the ring.
+func latticeChallenge(prefix, w []byte) []byte {
+	h := sha256.New()
+	h.Write(prefix)
+	h.Write(w)
+	return h.Sum(nil)
+}
+
+// latticeCommitment computes B*z - C(c)*t and packs it.
+func latticeCommitment(t []latticePoly, c []byte, z []latticePoly) []byte {
+	cp := latticeChallengePoly(c)
+	w := latticeImage(z)
+	for i := range w {
+		ct := cp.mul(&t[i])
+		w[i].sub(&ct)
+		w[i].reduce()
+	}
+
+	return packModQ(w)
+}
+
+// latticePoly is a polynomial of Z[X]/(X^N+1).
+// Its coefficients are reduced modulo q only when needed.
+type latticePoly [latticeN]int64
+
+// add adds b to the polynomial.
+func (p *latticePoly) add(b *latticePoly) {
+	for i := range p {
+		p[i] += b[i]
+	}
+}
+
+// sub subtracts b from the polynomial.
+func (p *latticePoly) sub(b *latticePoly) {
+	for i := range p {
+		p[i] -= b[i]
+	}
+}
+
+// reduce reduces the coefficients modulo q, in [0:q-1].
+func (p *latticePoly) reduce() {
+	for i := range p {
+		p[i] %= latticeQ
+		if p[i] < 0 {
+			p[i] += latticeQ
+		}
+	}
+}
+
+// mul multiplies two polynomials without reducing modulo q.
+// Callers make sure the coefficients are small enough not to overflow.
+func (p *latticePoly) mul(b *latticePoly) latticePoly {
+	var res latticePoly
+	for i, pi := range p {
+		if pi == 0 {
+			continue
+		}
+
+		for j, bj := range b {
+			if i+j < latticeN {
+				res[i+j] += pi * bj
+			} else {
+				res[i+j-latticeN] -= pi * bj
+			}
+		}
+	}
+
+	return res
+}
+
+var (
+	latticeMatrixOnce sync.Once
+	latticeMatrixA    [latticeK][latticeL]latticePoly
+)
+
+// latticeMatrix returns the public matrix A.
+// It is derived from the domain so that nobody knows a trapdoor for it.
+func latticeMatrix() *[latticeK][latticeL]latticePoly {
+	latticeMatrixOnce.Do(func() {
+		for i := range latticeMatrixA {
+			for j := range latticeMatrixA[i] {
+				st := newLatticeStream([]byte("matrix"), []byte{byte(i), byte(j)})
+				latticeMatrixA[i][j] = st.uniformModQ()
+			}
+		}
+	})
+
+	return &latticeMatrixA
+}
+
+// latticeImage computes B*v = A*v(1) + v(2) modulo q, with v = (v(1), v(2)).
+func latticeImage(v []latticePoly) []latticePoly {
+	a := latticeMatrix()
+	w := make([]latticePoly, latticeK)
+	for i := range w {
+		w[i] = v[latticeL+i]
+		for j := 0; j < latticeL; j++ {
+			prod := a[i][j].mul(&v[j])
+			w[i].add(&prod)
+		}
+
+		w[i].reduce()
+	}
+
+	return w
+}
+
+// latticeChallengePoly maps a hash to a polynomial with tau coefficients in
+// {-1,1} and the others set to 0.
+func latticeChallengePoly(c []byte) *latticePoly {
+	var p latticePoly
+	st := newLatticeStream([]byte("challenge"), c)
+	signs := binary.LittleEndian.Uint64(st.read(8))
+	for i := latticeN - latticeTau; i < latticeN; i++ {
+		j := int(st.read(1)[0])
+		for j > i {
+			j = int(st.read(1)[0])
+		}
+
+		p[i] = p[j]
+		p[j] = 1 - 2*int64(signs&1)
+		signs >>= 1
+	}
+
+	return &p
+}
+
+// latticeStream is a deterministic stream of pseudo-random bytes:
+// SHA256 in counter mode.
+type latticeStream struct {
+	key     []byte
+	counter uint64
+	buf     []byte
+}
+
+// newLatticeStream creates a stream seeded with the given data.
+func newLatticeStream(seed ...[]byte) *latticeStream {
+	h := sha256.New()
+	h.Write([]byte(latticeDomain))
+	for _, s := range seed {
+		writeLengthPrefixed(h, s)
+	}
+
+	return &latticeStream{key: h.Sum(nil)}
+}
+
+// randomLatticeStream creates a stream seeded with randomness.
+func randomLatticeStream(rand io.Reader) (*latticeStream, error) {
+	seed := make([]byte, latticeSeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return newLatticeStream([]byte("random"), seed), nil
+}
+
+// read reads the next n bytes of the stream.
+func (st *latticeStream) read(n int) []byte {
+	for len(st.buf) < n {
+		h := sha256.New()
+		h.Write(st.key)
+		binary.Write(h, binary.BigEndian, st.counter)
+		st.counter++
+		st.buf = h.Sum(st.buf)
+	}
+
+	b := st.buf[:n]
+	st.buf = st.buf[n:]
+	return b
+}
+
+// uniform samples a polynomial with coefficients uniform in [-limit:limit].
+func (st *latticeStream) uniform(limit int64) latticePoly {
+	var p latticePoly
+	mask := int64(1)<<uint(bits.Len64(uint64(2*limit))) - 1
+	for i := 0; i < latticeN; {
+		b := st.read(3)
+		v := (int64(b[0]) | int64(b[1])<<8 | int64(b[2])<<16) & mask
+		if v <= 2*limit {
+			p[i] = limit - v
+			i++
+		}
+	}
+
+	return p
+}
+
+// uniformModQ samples a polynomial with coefficients uniform in [0:q-1].
+func (st *latticeStream) uniformModQ() latticePoly {
+	var p latticePoly
+	for i := 0; i < latticeN; {
+		b := st.read(3)
+		v := int64(b[0]) | int64(b[1])<<8 | int64(b[2]&0x7f)<<16
+		if v < latticeQ {
+			p[i] = v
+			i++
+		}
+	}
+
+	return p
+}
+
+// packModQ packs polynomials reduced modulo q with 3-byte coefficients.
+func packModQ(ps []latticePoly) []byte {
+	b := make([]byte, 0, len(ps)*latticeN*3)
+	for _, p := range ps {
+		for _, v := range p {
+			b = append(b, byte(v), byte(v>>8), byte(v>>16))
+		}
+	}
+
+	return b
+}
+
+// unpackModQ unpacks count polynomials reduced modulo q.
+func unpackModQ(b []byte, count int) ([]latticePoly, bool) {
+	if len(b) != count*latticeN*3 {
+		return nil, false
+	}
+
+	ps := make([]latticePoly, count)
+	for i := range ps {
+		for j := range ps[i] {
+			k := 3 * (i*latticeN + j)
+			v := int64(b[k]) | int64(b[k+1])<<8 | int64(b[k+2])<<16
+			if v >= latticeQ {
+				return nil, false
+			}
+
+			ps[i][j] = v
+		}
+	}
+
+	return ps, true
+}
+
+// packResponse packs a response with 20-bit coefficients: each coefficient
+// z is encoded as gamma-z.
+func packResponse(z []latticePoly) []byte {
+	b := make([]byte, 0, latticeResponseSize)
+	for _, p := range z {
+		for j := 0; j < latticeN; j += 2 {
+			u0 := latticeGamma - p[j]
+			u1 := latticeGamma - p[j+1]
+			b = append(b, byte(u0), byte(u0>>8), byte(u0>>16)|byte(u1<<4), byte(u1>>4), byte(u1>>12))
+		}
+	}
+
+	return b
+}
+
+// unpackResponse unpacks a response and checks that its coefficients are
+// in [-(gamma-beta-1):gamma-beta-1].
+func unpackResponse(b []byte) ([]latticePoly, bool) {
+	if len(b) != latticeResponseSize {
+		return nil, false
+	}
+
+	z := make([]latticePoly, latticeK+latticeL)
+	for i := range z {
+		for j := 0; j < latticeN; j += 2 {
+			k := 5 * (i*latticeN + j) / 2
+			u0 := int64(b[k]) | int64(b[k+1])<<8 | int64(b[k+2]&0x0f)<<16
+			u1 := int64(b[k+2]>>4) | int64(b[k+3])<<4 | int64(b[k+4])<<12
+			z[i][j] = latticeGamma - u0
+			z[i][j+1] = latticeGamma - u1
+		}
+
+		for _, v := range z[i] {
+			if v < -(latticeGamma-latticeBeta-1) || latticeGamma-latticeBeta-1 < v {
+				return nil, false
+			}
+		}
+	}
+
+	return z, true
+}
+
+// Marshal marshals a lattice signature to a byte representation.
+func (sig *LatticeSignature) Marshal() ([]byte, error) {
+	keys := make([][]byte, len(sig.ring))
+	for i, pk := range sig.ring {
+		keys[i] = pk
+	}
+
+	return json.Marshal(struct {
+		R [][]byte
+		C []byte
+		Z [][]byte
+	}{
+		R: keys,
+		C: sig.c,
+		Z: sig.z,
+	})
+}
+
+// latticeDecodeOptions are the default options of lattice signatures,
+// whose ring members are much larger than elliptic curve ones.
+var latticeDecodeOptions = defaultOptionsFor(latticePublicKeySize + latticeResponseSize)
+
+// Unmarshal unmarshals a lattice signature from its byte representation.
+// It rejects signatures exceeding the default limits.
+func (sig *LatticeSignature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, latticeDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals a lattice signature from its byte
+// representation and checks it against the given limits.
+func (sig *LatticeSignature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
+	unmarshalled := struct {
+		R [][]byte
+		C []byte
+		Z [][]byte
+	}{}
+	err := opts.unmarshalJSON(data, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkRingSize(len(unmarshalled.R)); err != nil {
+		return err
+	}
+
+	if err := opts.checkResponses(len(unmarshalled.R), unmarshalled.Z); err != nil {
+		return err
+	}
+
+	if err := opts.checkHash("challenge", unmarshalled.C); err != nil {
+		return err
+	}
+
+	if opts.StrictFields {
+		for i := range unmarshalled.R {
+			if len(unmarshalled.R[i]) != latticePublicKeySize || len(unmarshalled.Z[i]) != latticeResponseSize {
+				return errors.Wrapf(ErrMalformedSignature, "invalid ring member at index %d", i)
+			}
+		}
+	}
+
+	ringKeys := make([]LatticePublicKey, len(unmarshalled.R))
+	for i, k := range unmarshalled.R {
+		ringKeys[i] = k
+	}
+
+	sig.ring = ringKeys
+	sig.c = unmarshalled.C
+	sig.z = unmarshalled.Z
+
+	return opts.checkEncoding(data, sig.Marshal)
+}
+
+// Encode encodes a lattice signature to a friendly string representation.
+func (sig *LatticeSignature) Encode() (string, error) {
+	b, err := sig.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a lattice signature from its friendly string representation.
+// It rejects signatures exceeding the default limits.
+func (sig *LatticeSignature) Decode(data string) error {
+	return sig.DecodeWithOptions(data, latticeDecodeOptions)
+}
+
+// DecodeWithOptions decodes a lattice signature from its friendly string
+// representation and checks it against the given limits.
+func (sig *LatticeSignature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
+}
@@ -0,0 +1,177 @@
+package ring
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLattice(t *testing.T) {
+	var privKeys []LatticePrivateKey
+	var ringKeys []LatticePublicKey
+	for i := 0; i < 3; i++ {
+		pk, sk := GenerateLattice(nil)
+		privKeys = append(privKeys, sk)
+		ringKeys = append(ringKeys, pk)
+	}
+
+	message := []byte("see you in thirty years")
+
+	t.Run("Generates deterministic keys from the seed", func(t *testing.T) {
+		pk, sk := GenerateLattice(bytes.NewReader(make([]byte, latticeSeedSize)))
+		assert.Len(t, sk, latticeSeedSize)
+		assert.Len(t, pk, latticePublicKeySize)
+		assert.Equal(t, pk, sk.Public())
+		assert.NotEqual(t, pk, ringKeys[0])
+	})
+
+	t.Run("Multiplies polynomials in Z[X]/(X^N+1)", func(t *testing.T) {
+		var x, y latticePoly
+		x[latticeN-1] = 1
+		y[1] = 3
+		y[2] = -2
+
+		// X^(N-1) * (3X - 2X^2) = 3X^N - 2X^(N+1) = -3 + 2X
+		p := x.mul(&y)
+		var expected latticePoly
+		expected[0] = -3
+		expected[1] = 2
+		assert.Equal(t, expected, p)
+	})
+
+	t.Run("Challenges have tau non-zero coefficients", func(t *testing.T) {
+		c := latticeChallengePoly([]byte("challenge"))
+		count := 0
+		for _, v := range c {
+			if v != 0 {
+				assert.True(t, v == 1 || v == -1)
+				count++
+			}
+		}
+
+		assert.Equal(t, latticeTau, count)
+		assert.Equal(t, c, latticeChallengePoly([]byte("challenge")))
+	})
+
+	t.Run("Packs and unpacks responses", func(t *testing.T) {
+		st := newLatticeStream([]byte("test"))
+		z := make([]latticePoly, latticeK+latticeL)
+		for i := range z {
+			z[i] = st.uniform(latticeGamma - latticeBeta - 1)
+		}
+
+		packed := packResponse(z)
+		assert.Len(t, packed, latticeResponseSize)
+
+		unpacked, ok := unpackResponse(packed)
+		assert.True(t, ok)
+		assert.Equal(t, z, unpacked)
+
+		z[3][7] = latticeGamma - latticeBeta
+		_, ok = unpackResponse(packResponse(z))
+		assert.False(t, ok)
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := privKeys[0].Sign(nil, nil, ringKeys, 0)
+		assert.EqualError(t, err, ErrEmptyMessage.Error())
+
+		_, err = privKeys[0].Sign(nil, message, ringKeys[:1], 0)
+		assert.EqualError(t, err, ErrRingTooSmall.Error())
+
+		_, err = privKeys[0].Sign(nil, message, ringKeys, 3)
+		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
+
+		_, err = privKeys[0].Sign(nil, message, ringKeys, 1)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+
+		_, err = LatticePrivateKey("short").Sign(nil, message, ringKeys, 0)
+		assert.EqualError(t, err, ErrInvalidPrivateKey.Error())
+
+		_, err = privKeys[0].Sign(nil, message, []LatticePublicKey{ringKeys[0], LatticePublicKey("key")}, 0)
+		assert.EqualError(t, err, ErrInvalidPublicKey.Error())
+	})
+
+	t.Run("Signs and verifies", func(t *testing.T) {
+		for i, sk := range privKeys {
+			sig, err := sk.Sign(nil, message, ringKeys, i)
+			assert.NoError(t, err, "Sign()")
+			assert.True(t, sig.Verify(message))
+			assert.False(t, sig.Verify([]byte("see you in twenty years")))
+			assert.Equal(t, ringKeys, sig.Ring())
+		}
+	})
+
+	t.Run("Rejects tampered signatures", func(t *testing.T) {
+		sig, err := privKeys[1].Sign(nil, message, ringKeys, 1)
+		assert.NoError(t, err, "Sign()")
+
+		sig.z[0][10] ^= 1
+		assert.False(t, sig.Verify(message))
+
+		sig, err = privKeys[1].Sign(nil, message, ringKeys, 1)
+		assert.NoError(t, err, "Sign()")
+
+		sig.ring = []LatticePublicKey{ringKeys[1], ringKeys[0], ringKeys[2]}
+		assert.False(t, sig.Verify(message))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := privKeys[2].Sign(nil, message, ringKeys, 2)
+		assert.NoError(t, err, "Sign()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &LatticeSignature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify(message))
+	})
+}
+
+func generateLatticeKeys(count int) ([]LatticePublicKey, []LatticePrivateKey) {
+	pubKeys := make([]LatticePublicKey, count)
+	privKeys := make([]LatticePrivateKey, count)
+	for i := 0; i < count; i++ {
+		pubKeys[i], privKeys[i] = GenerateLattice(nil)
+	}
+
+	return pubKeys, privKeys
+}
+
+func benchmarkLatticeSign(ringSize int, b *testing.B) {
+	pubKeys, privKeys := generateLatticeKeys(ringSize)
+	i := rand.Intn(ringSize)
+	message := []byte("Benchmark me like the french people do.")
+	for n := 0; n < b.N; n++ {
+		_, err := privKeys[i].Sign(nil, message, pubKeys, i)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLatticeSign3(b *testing.B)  { benchmarkLatticeSign(3, b) }
+func BenchmarkLatticeSign10(b *testing.B) { benchmarkLatticeSign(10, b) }
+
+func benchmarkLatticeVerify(ringSize int, b *testing.B) {
+	pubKeys, privKeys := generateLatticeKeys(ringSize)
+	i := rand.Intn(ringSize)
+	message := []byte("Benchmark me like the french people do.")
+	sig, err := privKeys[i].Sign(nil, message, pubKeys, i)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for n := 0; n < b.N; n++ {
+		valid := sig.Verify(message)
+		if !valid {
+			b.Fatalf("Signature verification failed.")
+		}
+	}
+}
+
+func BenchmarkLatticeVerify3(b *testing.B)  { benchmarkLatticeVerify(3, b) }
+func BenchmarkLatticeVerify10(b *testing.B) { benchmarkLatticeVerify(10, b) }
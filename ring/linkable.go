@@ -0,0 +1,387 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// linkableDomain separates linkable signature hashes from other hashes.
+const linkableDomain = "ring-signatures/linkable/v1"
+
+// LinkableSignature is a linkable ring signature (LSAG).
+// Like a Signature it hides the signer in the ring, but it also contains
+// a key image that is the same for every signature produced by the same
+// signer in the same scope. Two signatures from the same signer can thus
+// be detected without revealing who the signer is.
+type LinkableSignature struct {
+	ring     []PublicKey
+	scope    []byte
+	keyImage []byte
+	c        []byte
+	s        [][]byte
+}
+
+// Linkable signing algorithm (LSAG, Liu-Wei-Wong):
+//	* Let (P(0),...,P(R-1)) be all the public keys in the ring
+//	* Let H = Hp(scope) be a curve point nobody knows the discrete log of
+//	* Let r be the index of the actual signer and x(r) its private key
+//	* The key image is I = x(r)*H
+//	* Randomly choose k in [1:N-1]
+//	* Compute c(r+1 % R) = H(L || I || m || k*G || k*H)
+//	* for i := r+1 % R; i != r; i++%R:
+//		* Randomly choose s(i) in [1:N-1]
+//		* Compute c(i+1 % R) = H(L || I || m || s(i)*G + c(i)*P(i) || s(i)*H + c(i)*I)
+//	* Compute s(r) = k - c(r)*x(r) mod N
+//	* Output signature: (P(0),...,P(R-1),scope,I,c(0),s(0),...,s(R-1))
+
+// SignLinkable creates a linkable ring signature for the given message.
+// The scope defines where signatures can be linked: signatures from the
+// same signer share the same key image in a given scope, but signatures
+// from different scopes can't be linked.
+func (sk PrivateKey) SignLinkable(
+	rand io.Reader,
+	message []byte,
+	ringKeys []PublicKey,
+	signerIndex int,
+	scope []byte,
+) (*LinkableSignature, error) {
+	if len(message) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if signerIndex < 0 || len(ringKeys) <= signerIndex {
+		return nil, ErrInvalidSignerIndex
+	}
+
+	if len(ringKeys) < 2 {
+		return nil, ErrRingTooSmall
+	}
+
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if !bytes.Equal(sk.Public(), ringKeys[signerIndex]) {
+		return nil, ErrSignerMismatch
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+	r := len(ringKeys)
+
+	hx, hy := hashToPoint(curve, scope)
+	ix, iy := curve.ScalarMult(hx, hy, sk)
+	keyImage := elliptic.Marshal(curve, ix, iy)
+
+	prefix := linkablePrefix(ringKeys, scope, keyImage, message)
+	cs := make([][]byte, r)
+	ss := make([][]byte, r)
+
+	// Initialize the ring.
+
+	k, err := randomParam(curve, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	lx, ly := curve.ScalarBaseMult(k)
+	rx, ry := curve.ScalarMult(hx, hy, k)
+	cs[(signerIndex+1)%r] = linkableChallenge(curve, prefix, lx, ly, rx, ry)
+
+	// Iterate over the whole ring.
+
+	for i := (signerIndex + 1) % r; i != signerIndex; i = (i + 1) % r {
+		s, err := randomParam(curve, rand)
+		if err != nil {
+			return nil, err
+		}
+
+		ss[i] = s
+
+		px, py := elliptic.Unmarshal(curve, ringKeys[i])
+		if px == nil {
+			return nil, ErrInvalidPublicKey
+		}
+
+		lx, ly := addMult(curve, nil, nil, s, px, py, cs[i])
+		rx, ry := addMult(curve, hx, hy, s, ix, iy, cs[i])
+		cs[(i+1)%r] = linkableChallenge(curve, prefix, lx, ly, rx, ry)
+	}
+
+	// Close the ring.
+
+	valS := new(big.Int).Mul(new(big.Int).SetBytes(cs[signerIndex]), new(big.Int).SetBytes(sk))
+	valS.Sub(new(big.Int).SetBytes(k), valS)
+	valS.Mod(valS, n)
+	if valS.Sign() == 0 {
+		return nil, errors.New("could not produce ring signature")
+	}
+
+	ss[signerIndex] = valS.Bytes()
+
+	return &LinkableSignature{
+		ring:     ringKeys,
+		scope:    scope,
+		keyImage: keyImage,
+		c:        cs[0],
+		s:        ss,
+	}, nil
+}
+
+// Verify verifies the validity of the message signature.
+// It does not detail why the signature validation failed.
+func (sig *LinkableSignature) Verify(message []byte) bool {
+	if sig == nil || len(sig.ring) < 2 || len(sig.s) != len(sig.ring) || len(sig.c) == 0 {
+		return false
+	}
+
+	curve := elliptic.P384()
+	ix, iy := elliptic.Unmarshal(curve, sig.keyImage)
+	if ix == nil {
+		return false
+	}
+
+	hx, hy := hashToPoint(curve, sig.scope)
+	prefix := linkablePrefix(sig.ring, sig.scope, sig.keyImage, message)
+
+	c := sig.c
+	for i := 0; i < len(sig.ring); i++ {
+		px, py := elliptic.Unmarshal(curve, sig.ring[i])
+		if px == nil {
+			return false
+		}
+
+		lx, ly := addMult(curve, nil, nil, sig.s[i], px, py, c)
+		rx, ry := addMult(curve, hx, hy, sig.s[i], ix, iy, c)
+		c = linkableChallenge(curve, prefix, lx, ly, rx, ry)
+	}
+
+	return bytes.Equal(c, sig.c)
+}
+
+// VerifyStrict verifies the validity of the message signature and rejects
+// scalars that aren't canonically encoded (see DecodeOptions.StrictEncoding).
+// Signatures produced by SignLinkable always pass this check.
+func (sig *LinkableSignature) VerifyStrict(message []byte) bool {
+	return sig != nil && checkScalars(sig.s) == nil && sig.Verify(message)
+}
+
+// Ring returns the public keys of the ring that produced the signature.
+func (sig *LinkableSignature) Ring() []PublicKey {
+	return sig.ring
+}
+
+// Scope returns the scope in which the signature can be linked.
+func (sig *LinkableSignature) Scope() []byte {
+	return sig.scope
+}
+
+// KeyImage returns the key image identifying the signer in the signature's
+// scope, without revealing who the signer is.
+func (sig *LinkableSignature) KeyImage() []byte {
+	return sig.keyImage
+}
+
+// Linked returns true if both signatures were produced by the same signer
+// in the same scope.
+func (sig *LinkableSignature) Linked(other *LinkableSignature) bool {
+	return sig != nil && other != nil &&
+		bytes.Equal(sig.scope, other.scope) &&
+		bytes.Equal(sig.keyImage, other.keyImage)
+}
+
+// addMult computes s*B + c*P, where B is the base point when bx is nil.
+func addMult(curve elliptic.Curve, bx, by *big.Int, s []byte, px, py *big.Int, c []byte) (*big.Int, *big.Int) {
+	var x1, y1 *big.Int
+	if bx == nil {
+		x1, y1 = curve.ScalarBaseMult(s)
+	} else {
+		x1, y1 = curve.ScalarMult(bx, by, s)
+	}
+
+	x2, y2 := curve.ScalarMult(px, py, c)
+	return curve.Add(x1, y1, x2, y2)
+}
+
+// linkablePrefix hashes the parts of the challenge that don't change
+// along the ring.
+func linkablePrefix(ringKeys []PublicKey, scope, keyImage, message []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(linkableDomain))
+	for _, pk := range ringKeys {
+		writeLengthPrefixed(h, pk)
+	}
+
+	writeLengthPrefixed(h, scope)
+	writeLengthPrefixed(h, keyImage)
+	writeLengthPrefixed(h, message)
+	return h.Sum(nil)
+}
+
+// linkableChallenge computes the next challenge of the ring.
+func linkableChallenge(curve elliptic.Curve, prefix []byte, lx, ly, rx, ry *big.Int) []byte {
+	b := append([]byte{}, prefix...)
+	b = append(b, elliptic.Marshal(curve, lx, ly)...)
+	b = append(b, elliptic.Marshal(curve, rx, ry)...)
+	return hash(b)
+}
+
+// writeLengthPrefixed writes length-prefixed bytes.
+func writeLengthPrefixed(w io.Writer, b []byte) {
+	binary.Write(w, binary.BigEndian, uint64(len(b)))
+	w.Write(b)
+}
+
+// hashToPoint deterministically maps data to a curve point whose discrete
+// logarithm is unknown (try-and-increment).
+func hashToPoint(curve elliptic.Curve, data []byte) (*big.Int, *big.Int) {
+	params := curve.Params()
+	three := big.NewInt(3)
+
+	for counter := uint32(0); ; counter++ {
+		h := sha512.New384()
+		h.Write([]byte("ring-signatures/hash-to-point/v1"))
+		binary.Write(h, binary.BigEndian, counter)
+		h.Write(data)
+
+		x := new(big.Int).SetBytes(h.Sum(nil))
+		if x.Cmp(params.P) >= 0 {
+			continue
+		}
+
+		// y² = x³ - 3x + b
+		y2 := new(big.Int).Exp(x, three, params.P)
+		y2.Sub(y2, new(big.Int).Mul(x, three))
+		y2.Add(y2, params.B)
+		y2.Mod(y2, params.P)
+
+		y := new(big.Int).ModSqrt(y2, params.P)
+		if y == nil {
+			continue
+		}
+
+		// Always use the even root so that the point is deterministic.
+		if y.Bit(0) == 1 {
+			y.Sub(params.P, y)
+		}
+
+		if curve.IsOnCurve(x, y) {
+			return x, y
+		}
+	}
+}
+
+// Marshal marshals a linkable signature to a byte representation.
+func (sig *LinkableSignature) Marshal() ([]byte, error) {
+	return json.Marshal(struct {
+		R []PublicKey
+		L []byte
+		I []byte
+		C []byte
+		S [][]byte
+	}{
+		R: sig.ring,
+		L: sig.scope,
+		I: sig.keyImage,
+		C: sig.c,
+		S: sig.s,
+	})
+}
+
+// Unmarshal unmarshals a linkable signature from its byte representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
+func (sig *LinkableSignature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, DefaultDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals a linkable signature from its byte
+// representation and checks it against the given limits.
+func (sig *LinkableSignature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
+	unmarshalled := struct {
+		R []PublicKey
+		L []byte
+		I []byte
+		C []byte
+		S [][]byte
+	}{}
+	err := opts.unmarshalJSON(data, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkRing(unmarshalled.R, unmarshalled.S); err != nil {
+		return err
+	}
+
+	if err := opts.checkHash("challenge", unmarshalled.C); err != nil {
+		return err
+	}
+
+	if opts.StrictEncoding {
+		if err := checkScalars(unmarshalled.S); err != nil {
+			return err
+		}
+	}
+
+	if opts.StrictFields {
+		if x, _ := elliptic.Unmarshal(elliptic.P384(), unmarshalled.I); x == nil {
+			return errors.Wrap(ErrMalformedSignature, "invalid key image")
+		}
+	}
+
+	sig.ring = unmarshalled.R
+	sig.scope = unmarshalled.L
+	sig.keyImage = unmarshalled.I
+	sig.c = unmarshalled.C
+	sig.s = unmarshalled.S
+
+	return opts.checkEncoding(data, sig.Marshal)
+}
+
+// Encode encodes a linkable signature to a friendly string representation.
+func (sig *LinkableSignature) Encode() (string, error) {
+	b, err := sig.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a linkable signature from its friendly string representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
+func (sig *LinkableSignature) Decode(data string) error {
+	return sig.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes a linkable signature from its friendly string
+// representation and checks it against the given limits.
+func (sig *LinkableSignature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
+}
@@ -0,0 +1,105 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashToPoint(t *testing.T) {
+	curve := elliptic.P384()
+
+	x1, y1 := hashToPoint(curve, []byte("ballot 1"))
+	assert.True(t, curve.IsOnCurve(x1, y1))
+
+	x2, y2 := hashToPoint(curve, []byte("ballot 1"))
+	assert.Equal(t, x1, x2)
+	assert.Equal(t, y1, y2)
+
+	x3, _ := hashToPoint(curve, []byte("ballot 2"))
+	assert.NotEqual(t, x1, x3)
+}
+
+func TestLinkable(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, bobPriv := Generate(nil)
+	carolPub, _ := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub, carolPub}
+	scope := []byte("ballot 42")
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := alicePriv.SignLinkable(nil, nil, ringKeys, 0, scope)
+		assert.EqualError(t, err, ErrEmptyMessage.Error())
+
+		_, err = alicePriv.SignLinkable(nil, []byte("yes"), ringKeys[:1], 0, scope)
+		assert.EqualError(t, err, ErrRingTooSmall.Error())
+
+		_, err = alicePriv.SignLinkable(nil, []byte("yes"), ringKeys, 3, scope)
+		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
+
+		_, err = alicePriv.SignLinkable(nil, []byte("yes"), ringKeys, 1, scope)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+	})
+
+	t.Run("Signs and verifies", func(t *testing.T) {
+		for i, signer := range []PrivateKey{alicePriv, bobPriv} {
+			sig, err := signer.SignLinkable(nil, []byte("yes"), ringKeys, i, scope)
+			assert.NoError(t, err, "SignLinkable()")
+			assert.True(t, sig.Verify([]byte("yes")))
+			assert.False(t, sig.Verify([]byte("no")))
+		}
+	})
+
+	t.Run("Links signatures from the same signer", func(t *testing.T) {
+		sig1, err := alicePriv.SignLinkable(nil, []byte("yes"), ringKeys, 0, scope)
+		assert.NoError(t, err, "SignLinkable()")
+
+		sig2, err := alicePriv.SignLinkable(nil, []byte("no"), []PublicKey{bobPub, alicePub}, 1, scope)
+		assert.NoError(t, err, "SignLinkable()")
+		assert.True(t, sig1.Linked(sig2))
+
+		sig3, err := bobPriv.SignLinkable(nil, []byte("yes"), ringKeys, 1, scope)
+		assert.NoError(t, err, "SignLinkable()")
+		assert.False(t, sig1.Linked(sig3))
+
+		sig4, err := alicePriv.SignLinkable(nil, []byte("yes"), ringKeys, 0, []byte("ballot 43"))
+		assert.NoError(t, err, "SignLinkable()")
+		assert.False(t, sig1.Linked(sig4))
+		assert.NotEqual(t, sig1.KeyImage(), sig4.KeyImage())
+	})
+
+	t.Run("Rejects tampered signatures", func(t *testing.T) {
+		sig, err := alicePriv.SignLinkable(nil, []byte("yes"), ringKeys, 0, scope)
+		assert.NoError(t, err, "SignLinkable()")
+
+		forged := *sig
+		forged.scope = []byte("ballot 43")
+		assert.False(t, forged.Verify([]byte("yes")))
+
+		// Using another key image would allow voting twice.
+		other, err := bobPriv.SignLinkable(nil, []byte("yes"), ringKeys, 1, scope)
+		assert.NoError(t, err, "SignLinkable()")
+		forged = *sig
+		forged.keyImage = other.keyImage
+		assert.False(t, forged.Verify([]byte("yes")))
+
+		forged = *sig
+		forged.keyImage = []byte("not a point")
+		assert.False(t, forged.Verify([]byte("yes")))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := bobPriv.SignLinkable(nil, []byte("yes"), ringKeys, 1, scope)
+		assert.NoError(t, err, "SignLinkable()")
+
+		s, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &LinkableSignature{}
+		assert.NoError(t, decoded.Decode(s), "Decode()")
+		assert.EqualValues(t, sig, decoded)
+		assert.True(t, decoded.Verify([]byte("yes")))
+		assert.True(t, decoded.Linked(sig))
+	})
+}
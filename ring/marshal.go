@@ -5,36 +5,118 @@ import (
 	"encoding/json"
 )
 
+// marshalledAttributes is the byte representation of signed attributes.
+type marshalledAttributes struct {
+	T int64             `json:",omitempty"`
+	X int64             `json:",omitempty"`
+	C string            `json:",omitempty"`
+	K map[string]string `json:",omitempty"`
+}
+
 // Marshal marshals a signature to a byte representation.
 func (sig *Signature) Marshal() ([]byte, error) {
+	var attrs *marshalledAttributes
+	if sig.attrs != nil {
+		attrs = &marshalledAttributes{
+			T: timestampOf(sig.attrs.Timestamp),
+			X: timestampOf(sig.attrs.Expiry),
+			C: sig.attrs.Context,
+			K: sig.attrs.Claims,
+		}
+	}
+
+	var salt, commitment []byte
+	if sig.claim != nil {
+		salt = sig.claim.salt
+		commitment = sig.claim.commitment
+	}
+
 	return json.Marshal(struct {
 		R []PublicKey
 		S [][]byte
 		E []byte
+		A *marshalledAttributes `json:",omitempty"`
+		N []byte                `json:",omitempty"`
+		C []byte                `json:",omitempty"`
 	}{
 		R: sig.ring,
 		S: sig.s,
 		E: sig.e,
+		A: attrs,
+		N: salt,
+		C: commitment,
 	})
 }
 
 // Unmarshal unmarshals a signature from its byte representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
 func (sig *Signature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, DefaultDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals a signature from its byte representation
+// and checks it against the given limits.
+func (sig *Signature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
 	unmarshalled := struct {
 		R []PublicKey
 		S [][]byte
 		E []byte
+		A *marshalledAttributes
+		N []byte
+		C []byte
 	}{}
-	err := json.Unmarshal(data, &unmarshalled)
+	err := opts.unmarshalJSON(data, &unmarshalled)
 	if err != nil {
 		return err
 	}
 
+	if err := opts.checkRing(unmarshalled.R, unmarshalled.S); err != nil {
+		return err
+	}
+
+	if err := opts.checkHash("challenge", unmarshalled.E); err != nil {
+		return err
+	}
+
+	if opts.StrictEncoding {
+		if err := checkScalars(unmarshalled.S); err != nil {
+			return err
+		}
+	}
+
+	if len(unmarshalled.C) > 0 || len(unmarshalled.N) > 0 {
+		if err := opts.checkHash("claim salt", unmarshalled.N); err != nil {
+			return err
+		}
+
+		if err := opts.checkPoint("claim commitment", unmarshalled.C); err != nil {
+			return err
+		}
+	}
+
 	sig.ring = unmarshalled.R
 	sig.e = unmarshalled.E
 	sig.s = unmarshalled.S
+	sig.attrs = nil
+	sig.claim = nil
+
+	if a := unmarshalled.A; a != nil {
+		sig.attrs = &Attributes{
+			Timestamp: unixTime(a.T),
+			Expiry:    unixTime(a.X),
+			Context:   a.C,
+			Claims:    a.K,
+		}
+	}
 
-	return nil
+	if len(unmarshalled.C) > 0 {
+		sig.claim = &claimCommitment{
+			salt:       unmarshalled.N,
+			commitment: unmarshalled.C,
+		}
+	}
+
+	return opts.checkEncoding(data, sig.Marshal)
 }
 
 // Encode encodes a signature to a friendly string representation.
@@ -48,16 +130,25 @@ func (sig *Signature) Encode() (string, error) {
 }
 
 // Decode decodes a signature from its friendly string representation.
+// It rejects signatures exceeding the limits of DefaultDecodeOptions.
 func (sig *Signature) Decode(data string) error {
-	b, err := base64.StdEncoding.DecodeString(data)
+	return sig.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes a signature from its friendly string
+// representation and checks it against the given limits.
+func (sig *Signature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
 	if err != nil {
 		return err
 	}
 
-	err = sig.Unmarshal(b)
-	if err != nil {
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
 		return err
 	}
 
-	return nil
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
 }
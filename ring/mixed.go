@@ -0,0 +1,442 @@
+package ring
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedMixedKey is returned when a mixed ring contains a key
+// that can't be used in a ring.
+var ErrUnsupportedMixedKey = errors.New("unsupported key type: mixed rings accept EC keys on P-256 and P-384 and RSA keys")
+
+// mixedDomain separates mixed ring signature hashes from other hashes.
+const mixedDomain = "ring-signatures/mixed/v1"
+
+// MixedSignature is a ring signature whose ring can contain keys of
+// different types (Abe-Ohkubo-Suzuki 1-out-of-n signature).
+// Ring members can use EC keys on P-256 or P-384 (*ecdsa.PublicKey or
+// PublicKey) and RSA keys (*rsa.PublicKey).
+type MixedSignature struct {
+	ring []crypto.PublicKey
+	c    []byte
+	s    [][]byte
+}
+
+// Mixed signing algorithm (Abe-Ohkubo-Suzuki):
+//	* Let (P(0),...,P(R-1)) be the public keys in the ring and L their encoding
+//	* Each member i defines a commitment function from the challenge c and
+//	  their response s:
+//		* EC keys on the curve with base point G(i) and order N(i):
+//		  A(i)(c, s) = s*G(i) + c*P(i), with s in [1:N(i)-1]
+//		* RSA keys with modulus n(i) and exponent e(i):
+//		  A(i)(c, s) = c + s^e(i) mod n(i), with s in [0:n(i)-1]
+//	* Let r be the index of the actual signer
+//	* Randomly choose the signer's commitment:
+//		* EC keys: a = k*G(r) for a random k
+//		* RSA keys: a is random in [0:n(r)-1]
+//	* Compute c(r+1 % R) = H(L || m || a)
+//	* for i := r+1 % R; i != r; i++%R:
+//		* Randomly choose s(i)
+//		* Compute c(i+1 % R) = H(L || m || A(i)(c(i), s(i)))
+//	* Close the ring so that A(r)(c(r), s(r)) = a:
+//		* EC keys: s(r) = k - c(r)*x(r) mod N(r)
+//		* RSA keys: s(r) = (a - c(r))^d(r) mod n(r)
+//	* Output signature: (P(0),...,P(R-1),c(0),s(0),...,s(R-1))
+
+// SignMixed creates a ring signature of the given message with a ring
+// mixing key types.
+// The private key can be a PrivateKey, an *ecdsa.PrivateKey or an
+// *rsa.PrivateKey and must match the public key at the signer index.
+func SignMixed(
+	rand io.Reader,
+	message []byte,
+	ringKeys []crypto.PublicKey,
+	signerIndex int,
+	sk crypto.PrivateKey,
+) (*MixedSignature, error) {
+	if len(message) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if signerIndex < 0 || len(ringKeys) <= signerIndex {
+		return nil, ErrInvalidSignerIndex
+	}
+
+	if len(ringKeys) < 2 {
+		return nil, ErrRingTooSmall
+	}
+
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	normalized, err := normalizeMixedRing(ringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := mixedPrefix(normalized, message)
+	if err != nil {
+		return nil, err
+	}
+
+	r := len(normalized)
+	cs := make([][]byte, r)
+	ss := make([][]byte, r)
+
+	// Initialize the ring.
+
+	var a []byte
+	var k *big.Int
+	switch signer := normalized[signerIndex].(type) {
+	case *ecdsa.PublicKey:
+		priv, ok := mixedECPrivateKey(sk, signer.Curve)
+		if !ok || priv.X.Cmp(signer.X) != 0 || priv.Y.Cmp(signer.Y) != 0 {
+			return nil, ErrSignerMismatch
+		}
+
+		kb, err := randomParam(signer.Curve, rand)
+		if err != nil {
+			return nil, err
+		}
+
+		k = new(big.Int).SetBytes(kb)
+		ax, ay := signer.Curve.ScalarBaseMult(kb)
+		a = elliptic.Marshal(signer.Curve, ax, ay)
+	case *rsa.PublicKey:
+		priv, ok := sk.(*rsa.PrivateKey)
+		if !ok || !sameRSAKey(&priv.PublicKey, signer) {
+			return nil, ErrSignerMismatch
+		}
+
+		k, err = crand.Int(rand, signer.N)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		a = leftPad(k.Bytes(), (signer.N.BitLen()+7)/8)
+	}
+
+	cs[(signerIndex+1)%r] = hash(append(append([]byte{}, prefix...), a...))
+
+	// Iterate over the whole ring.
+
+	for i := (signerIndex + 1) % r; i != signerIndex; i = (i + 1) % r {
+		var s []byte
+		switch pk := normalized[i].(type) {
+		case *ecdsa.PublicKey:
+			s, err = randomParam(pk.Curve, rand)
+		case *rsa.PublicKey:
+			var v *big.Int
+			v, err = crand.Int(rand, pk.N)
+			if v != nil {
+				s = v.Bytes()
+			}
+		}
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		ss[i] = s
+		cs[(i+1)%r] = hash(append(append([]byte{}, prefix...), mixedCommitment(normalized[i], cs[i], s)...))
+	}
+
+	// Close the ring.
+
+	c := new(big.Int).SetBytes(cs[signerIndex])
+	switch signer := normalized[signerIndex].(type) {
+	case *ecdsa.PublicKey:
+		priv, _ := mixedECPrivateKey(sk, signer.Curve)
+		s := new(big.Int).Mul(c, priv.D)
+		s.Sub(k, s)
+		s.Mod(s, signer.Curve.Params().N)
+		if s.Sign() == 0 {
+			return nil, errors.New("could not produce ring signature")
+		}
+
+		ss[signerIndex] = s.Bytes()
+	case *rsa.PublicKey:
+		priv := sk.(*rsa.PrivateKey)
+		s := new(big.Int).Sub(k, c)
+		s.Mod(s, signer.N)
+		s, err := rsaDecrypt(rand, priv, s)
+		if err != nil {
+			return nil, err
+		}
+
+		ss[signerIndex] = s.Bytes()
+	}
+
+	return &MixedSignature{
+		ring: normalized,
+		c:    cs[0],
+		s:    ss,
+	}, nil
+}
+
+// Verify verifies the validity of the message signature.
+// It does not detail why the signature validation failed.
+func (sig *MixedSignature) Verify(message []byte) bool {
+	if sig == nil || len(sig.ring) < 2 || len(sig.s) != len(sig.ring) || len(sig.c) == 0 {
+		return false
+	}
+
+	prefix, err := mixedPrefix(sig.ring, message)
+	if err != nil {
+		return false
+	}
+
+	c := sig.c
+	for i, pk := range sig.ring {
+		a := mixedCommitment(pk, c, sig.s[i])
+		if a == nil {
+			return false
+		}
+
+		c = hash(append(append([]byte{}, prefix...), a...))
+	}
+
+	return bytes.Equal(c, sig.c)
+}
+
+// Ring returns the public keys of the ring that produced the signature.
+// P-384 keys given as PublicKey are returned as *ecdsa.PublicKey.
+func (sig *MixedSignature) Ring() []crypto.PublicKey {
+	return sig.ring
+}
+
+// mixedCommitment computes the commitment A(i)(c, s) of a ring member,
+// or nil if the response is invalid.
+func mixedCommitment(pk crypto.PublicKey, c, s []byte) []byte {
+	switch pk := pk.(type) {
+	case *ecdsa.PublicKey:
+		n := pk.Curve.Params().N
+		cc := new(big.Int).Mod(new(big.Int).SetBytes(c), n)
+		if new(big.Int).SetBytes(s).Cmp(n) >= 0 {
+			return nil
+		}
+
+		x, y := addMult(pk.Curve, nil, nil, s, pk.X, pk.Y, cc.Bytes())
+		return elliptic.Marshal(pk.Curve, x, y)
+	case *rsa.PublicKey:
+		v := new(big.Int).SetBytes(s)
+		if v.Cmp(pk.N) >= 0 {
+			return nil
+		}
+
+		v.Exp(v, big.NewInt(int64(pk.E)), pk.N)
+		v.Add(v, new(big.Int).SetBytes(c))
+		v.Mod(v, pk.N)
+		return leftPad(v.Bytes(), (pk.N.BitLen()+7)/8)
+	default:
+		return nil
+	}
+}
+
+// mixedECPrivateKey returns the EC private key on the given curve.
+func mixedECPrivateKey(sk crypto.PrivateKey, curve elliptic.Curve) (*ecdsa.PrivateKey, bool) {
+	switch sk := sk.(type) {
+	case *ecdsa.PrivateKey:
+		return sk, sk.Curve == curve
+	case PrivateKey:
+		if curve != elliptic.P384() {
+			return nil, false
+		}
+
+		x, y := curve.ScalarBaseMult(sk)
+		return &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+			D:         new(big.Int).SetBytes(sk),
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// normalizeMixedRing validates the ring keys and converts P-384 keys given
+// as PublicKey to *ecdsa.PublicKey.
+func normalizeMixedRing(ringKeys []crypto.PublicKey) ([]crypto.PublicKey, error) {
+	normalized := make([]crypto.PublicKey, len(ringKeys))
+	for i, pk := range ringKeys {
+		switch pk := pk.(type) {
+		case PublicKey:
+			curve := elliptic.P384()
+			x, y := elliptic.Unmarshal(curve, pk)
+			if x == nil {
+				return nil, ErrInvalidPublicKey
+			}
+
+			normalized[i] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		case *ecdsa.PublicKey:
+			if pk.Curve != elliptic.P256() && pk.Curve != elliptic.P384() {
+				return nil, ErrUnsupportedMixedKey
+			}
+
+			if pk.X == nil || pk.Y == nil || !pk.Curve.IsOnCurve(pk.X, pk.Y) {
+				return nil, ErrInvalidPublicKey
+			}
+
+			normalized[i] = pk
+		case *rsa.PublicKey:
+			if err := checkRSAKey(pk); err != nil {
+				return nil, err
+			}
+
+			normalized[i] = pk
+		default:
+			return nil, ErrUnsupportedMixedKey
+		}
+	}
+
+	return normalized, nil
+}
+
+// mixedPrefix hashes the ring and the message.
+func mixedPrefix(ringKeys []crypto.PublicKey, message []byte) ([]byte, error) {
+	h := sha256.New()
+	h.Write([]byte(mixedDomain))
+	for _, pk := range ringKeys {
+		der, err := x509.MarshalPKIXPublicKey(pk)
+		if err != nil {
+			return nil, ErrUnsupportedMixedKey
+		}
+
+		writeLengthPrefixed(h, der)
+	}
+
+	writeLengthPrefixed(h, message)
+	return h.Sum(nil), nil
+}
+
+// Marshal marshals a mixed signature to a byte representation.
+// Public keys are encoded in PKIX form.
+func (sig *MixedSignature) Marshal() ([]byte, error) {
+	keys := make([][]byte, len(sig.ring))
+	for i, pk := range sig.ring {
+		der, err := x509.MarshalPKIXPublicKey(pk)
+		if err != nil {
+			return nil, ErrUnsupportedMixedKey
+		}
+
+		keys[i] = der
+	}
+
+	return json.Marshal(struct {
+		R [][]byte
+		C []byte
+		S [][]byte
+	}{
+		R: keys,
+		C: sig.c,
+		S: sig.s,
+	})
+}
+
+// mixedDecodeOptions are the default options of mixed signatures, whose
+// ring members can be large RSA keys.
+var mixedDecodeOptions = defaultOptionsFor(rsaMaxBits/4 + 64)
+
+// Unmarshal unmarshals a mixed signature from its byte representation.
+// It rejects signatures exceeding the default limits.
+func (sig *MixedSignature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, mixedDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals a mixed signature from its byte
+// representation and checks it against the given limits.
+// RSA keys larger than 8192 bits are always rejected.
+func (sig *MixedSignature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
+	unmarshalled := struct {
+		R [][]byte
+		C []byte
+		S [][]byte
+	}{}
+	err := opts.unmarshalJSON(data, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkRingSize(len(unmarshalled.R)); err != nil {
+		return err
+	}
+
+	if err := opts.checkResponses(len(unmarshalled.R), unmarshalled.S); err != nil {
+		return err
+	}
+
+	if err := opts.checkHash("challenge", unmarshalled.C); err != nil {
+		return err
+	}
+
+	ringKeys := make([]crypto.PublicKey, len(unmarshalled.R))
+	for i, der := range unmarshalled.R {
+		pk, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return ErrInvalidPublicKey
+		}
+
+		ringKeys[i] = pk
+	}
+
+	normalized, err := normalizeMixedRing(ringKeys)
+	if err != nil {
+		return err
+	}
+
+	sig.ring = normalized
+	sig.c = unmarshalled.C
+	sig.s = unmarshalled.S
+
+	return opts.checkEncoding(data, sig.Marshal)
+}
+
+// Encode encodes a mixed signature to a friendly string representation.
+func (sig *MixedSignature) Encode() (string, error) {
+	b, err := sig.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a mixed signature from its friendly string representation.
+// It rejects signatures exceeding the default limits.
+func (sig *MixedSignature) Decode(data string) error {
+	return sig.DecodeWithOptions(data, mixedDecodeOptions)
+}
+
+// DecodeWithOptions decodes a mixed signature from its friendly string
+// representation and checks it against the given limits.
+func (sig *MixedSignature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
+}
@@ -0,0 +1,96 @@
+package ring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMixed(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err, "ecdsa.GenerateKey()")
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err, "ecdsa.GenerateKey()")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err, "rsa.GenerateKey()")
+
+	ringPub, ringPriv := Generate(nil)
+
+	privKeys := []crypto.PrivateKey{p256, p384, rsaKey, ringPriv}
+	ringKeys := []crypto.PublicKey{&p256.PublicKey, &p384.PublicKey, &rsaKey.PublicKey, ringPub}
+	message := []byte("one ring to sign them all")
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := SignMixed(nil, nil, ringKeys, 0, p256)
+		assert.EqualError(t, err, ErrEmptyMessage.Error())
+
+		_, err = SignMixed(nil, message, ringKeys[:1], 0, p256)
+		assert.EqualError(t, err, ErrRingTooSmall.Error())
+
+		_, err = SignMixed(nil, message, ringKeys, 4, p256)
+		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
+
+		_, err = SignMixed(nil, message, ringKeys, 1, p256)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+
+		_, err = SignMixed(nil, message, ringKeys, 2, p256)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+	})
+
+	t.Run("Rejects unsupported keys", func(t *testing.T) {
+		p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		assert.NoError(t, err, "ecdsa.GenerateKey()")
+
+		_, err = SignMixed(nil, message, []crypto.PublicKey{&p256.PublicKey, &p224.PublicKey}, 0, p256)
+		assert.EqualError(t, err, ErrUnsupportedMixedKey.Error())
+
+		_, err = SignMixed(nil, message, []crypto.PublicKey{&p256.PublicKey, "key"}, 0, p256)
+		assert.EqualError(t, err, ErrUnsupportedMixedKey.Error())
+
+		_, err = SignMixed(nil, message, []crypto.PublicKey{&p256.PublicKey, PublicKey("key")}, 0, p256)
+		assert.EqualError(t, err, ErrInvalidPublicKey.Error())
+	})
+
+	t.Run("Signs and verifies", func(t *testing.T) {
+		for i, sk := range privKeys {
+			sig, err := SignMixed(nil, message, ringKeys, i, sk)
+			assert.NoError(t, err, "SignMixed()")
+			assert.True(t, sig.Verify(message))
+			assert.False(t, sig.Verify([]byte("one ring to find them")))
+			assert.Len(t, sig.Ring(), len(ringKeys))
+		}
+	})
+
+	t.Run("Rejects tampered signatures", func(t *testing.T) {
+		sig, err := SignMixed(nil, message, ringKeys, 2, rsaKey)
+		assert.NoError(t, err, "SignMixed()")
+
+		sig.s[0][3] ^= 1
+		assert.False(t, sig.Verify(message))
+
+		sig, err = SignMixed(nil, message, ringKeys, 2, rsaKey)
+		assert.NoError(t, err, "SignMixed()")
+
+		sig.ring[0], sig.ring[1] = sig.ring[1], sig.ring[0]
+		assert.False(t, sig.Verify(message))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := SignMixed(nil, message, ringKeys, 0, p256)
+		assert.NoError(t, err, "SignMixed()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &MixedSignature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify(message))
+	})
+}
@@ -0,0 +1,223 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrCannotRepudiate is returned when the signer tries to repudiate
+	// their own signature.
+	ErrCannotRepudiate = errors.New("the key produced the signature and can't repudiate it")
+
+	// ErrInvalidRepudiation is returned when a repudiation doesn't prove
+	// that the ring member didn't produce the signature.
+	ErrInvalidRepudiation = errors.New("invalid repudiation")
+)
+
+// repudiationDomain separates repudiation hashes from other hashes.
+const repudiationDomain = "ring-signatures/repudiation/v1"
+
+// Repudiation proves that a ring member did not produce a linkable
+// signature, without revealing who did.
+//
+// The member reveals their key image in the signature's scope and proves
+// it matches their public key. Since it differs from the signature's key
+// image, they aren't the signer. Revealing it makes the member's other
+// signatures in that scope linkable to them, so it should only be used in
+// scopes where they don't need to sign anonymously.
+// Regular signatures can't be repudiated: they hide the signer perfectly.
+type Repudiation struct {
+	member   PublicKey
+	keyImage []byte
+	c        []byte
+	s        []byte
+}
+
+// Repudiation proof (Chaum-Pedersen), with x the member's private key:
+//	* Let H = Hp(scope) and J = x*H the member's key image
+//	* Compute the nonce k = HMAC(x, I || J) mod N
+//	* Compute c = H(scope || I || P || J || k*G || k*H)
+//	* Compute s = k - c*x mod N
+//	* Verifiers check that c = H(scope || I || P || J || s*G + c*P || s*H + c*J)
+//	  and that J differs from the signature's key image I
+
+// Repudiate proves that the private key did not produce the signature.
+// The private key must belong to a ring member.
+func (sk PrivateKey) Repudiate(sig *LinkableSignature) (*Repudiation, error) {
+	if sig == nil {
+		return nil, ErrInvalidSignature
+	}
+
+	pk := sk.Public()
+	if !NewKeySet(sig.ring...).Contains(pk) {
+		return nil, ErrSignerNotInRing
+	}
+
+	curve := elliptic.P384()
+	n := curve.Params().N
+
+	hx, hy := hashToPoint(curve, sig.scope)
+	jx, jy := curve.ScalarMult(hx, hy, sk)
+	keyImage := elliptic.Marshal(curve, jx, jy)
+	if bytes.Equal(keyImage, sig.keyImage) {
+		return nil, ErrCannotRepudiate
+	}
+
+	// The nonce is derived from the private key and the signature, so
+	// that repudiating doesn't need randomness.
+	mac := hmac.New(sha512.New, sk)
+	mac.Write([]byte(repudiationDomain))
+	mac.Write(sig.keyImage)
+	mac.Write(keyImage)
+	k := new(big.Int).SetBytes(mac.Sum(nil))
+	k.Mod(k, n)
+	if k.Sign() == 0 {
+		return nil, errors.New("could not produce repudiation")
+	}
+
+	ax, ay := curve.ScalarBaseMult(k.Bytes())
+	bx, by := curve.ScalarMult(hx, hy, k.Bytes())
+	c := repudiationChallenge(curve, sig, pk, keyImage, ax, ay, bx, by)
+
+	s := new(big.Int).Mul(new(big.Int).SetBytes(c), new(big.Int).SetBytes(sk))
+	s.Sub(k, s)
+	s.Mod(s, n)
+
+	return &Repudiation{member: pk, keyImage: keyImage, c: c, s: s.Bytes()}, nil
+}
+
+// Member returns the public key of the ring member who repudiates the
+// signature.
+func (rep *Repudiation) Member() PublicKey {
+	return rep.member
+}
+
+// VerifyRepudiation verifies the validity of the message signature, then
+// that the repudiation's member is in the signature's ring and did not
+// produce it.
+func (sig *LinkableSignature) VerifyRepudiation(message []byte, rep *Repudiation) error {
+	if sig == nil || !sig.Verify(message) {
+		return ErrInvalidSignature
+	}
+
+	if rep == nil || len(rep.c) == 0 {
+		return ErrInvalidRepudiation
+	}
+
+	if !NewKeySet(sig.ring...).Contains(rep.member) {
+		return ErrSignerNotInRing
+	}
+
+	if bytes.Equal(rep.keyImage, sig.keyImage) {
+		return ErrCannotRepudiate
+	}
+
+	curve := elliptic.P384()
+	px, py := elliptic.Unmarshal(curve, rep.member)
+	if px == nil {
+		return ErrInvalidPublicKey
+	}
+
+	jx, jy := elliptic.Unmarshal(curve, rep.keyImage)
+	if jx == nil {
+		return ErrInvalidRepudiation
+	}
+
+	hx, hy := hashToPoint(curve, sig.scope)
+	ax, ay := addMult(curve, nil, nil, rep.s, px, py, rep.c)
+	bx, by := addMult(curve, hx, hy, rep.s, jx, jy, rep.c)
+	if !bytes.Equal(rep.c, repudiationChallenge(curve, sig, rep.member, rep.keyImage, ax, ay, bx, by)) {
+		return ErrInvalidRepudiation
+	}
+
+	return nil
+}
+
+// repudiationChallenge computes the challenge of a repudiation proof.
+func repudiationChallenge(
+	curve elliptic.Curve,
+	sig *LinkableSignature,
+	member PublicKey,
+	keyImage []byte,
+	ax, ay, bx, by *big.Int,
+) []byte {
+	h := sha256.New()
+	h.Write([]byte(repudiationDomain))
+	writeLengthPrefixed(h, sig.scope)
+	writeLengthPrefixed(h, sig.keyImage)
+	writeLengthPrefixed(h, member)
+	writeLengthPrefixed(h, keyImage)
+	h.Write(elliptic.Marshal(curve, ax, ay))
+	h.Write(elliptic.Marshal(curve, bx, by))
+	return h.Sum(nil)
+}
+
+// Encode encodes a repudiation to a friendly string representation.
+func (rep *Repudiation) Encode() (string, error) {
+	b, err := json.Marshal(struct {
+		P []byte
+		I []byte
+		C []byte
+		S []byte
+	}{
+		P: rep.member,
+		I: rep.keyImage,
+		C: rep.c,
+		S: rep.s,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a repudiation from its friendly string representation.
+// It rejects repudiations exceeding the limits of DefaultDecodeOptions.
+func (rep *Repudiation) Decode(data string) error {
+	return rep.DecodeWithOptions(data, DefaultDecodeOptions)
+}
+
+// DecodeWithOptions decodes a repudiation from its friendly string representation
+// and checks it against the given limits.
+func (rep *Repudiation) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	unmarshalled := struct {
+		P []byte
+		I []byte
+		C []byte
+		S []byte
+	}{}
+	err = opts.unmarshalJSON(b, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkProof(unmarshalled.P, unmarshalled.C, unmarshalled.S); err != nil {
+		return err
+	}
+
+	if err := opts.checkPoint("key image", unmarshalled.I); err != nil {
+		return err
+	}
+
+	rep.member = unmarshalled.P
+	rep.keyImage = unmarshalled.I
+	rep.c = unmarshalled.C
+	rep.s = unmarshalled.S
+
+	return nil
+}
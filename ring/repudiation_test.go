@@ -0,0 +1,88 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepudiation(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, bobPriv := Generate(nil)
+	carolPub, carolPriv := Generate(nil)
+	_, evePriv := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub, carolPub}
+	scope := []byte("feedback/2018-10")
+
+	message := []byte("the meetings are too long")
+	sig, err := alicePriv.SignLinkable(nil, message, ringKeys, 0, scope)
+	assert.NoError(t, err, "SignLinkable()")
+
+	t.Run("Non-signers repudiate", func(t *testing.T) {
+		for _, member := range []PrivateKey{bobPriv, carolPriv} {
+			rep, err := member.Repudiate(sig)
+			assert.NoError(t, err, "Repudiate()")
+			assert.Equal(t, member.Public(), rep.Member())
+			assert.NoError(t, sig.VerifyRepudiation(message, rep))
+		}
+	})
+
+	t.Run("The signer can't repudiate", func(t *testing.T) {
+		_, err := alicePriv.Repudiate(sig)
+		assert.Equal(t, ErrCannotRepudiate, err)
+
+		// Bob's proof can't be reused by Alice.
+		rep, err := bobPriv.Repudiate(sig)
+		assert.NoError(t, err, "Repudiate()")
+		forged := *rep
+		forged.member = alicePub
+		assert.Equal(t, ErrInvalidRepudiation, sig.VerifyRepudiation(message, &forged))
+
+		// Alice can't use a fake key image.
+		forged = *rep
+		forged.member = alicePub
+		forged.keyImage = carolPub
+		assert.Equal(t, ErrInvalidRepudiation, sig.VerifyRepudiation(message, &forged))
+
+		forged = *rep
+		forged.keyImage = sig.keyImage
+		assert.Equal(t, ErrCannotRepudiate, sig.VerifyRepudiation(message, &forged))
+	})
+
+	t.Run("Outsiders can't repudiate", func(t *testing.T) {
+		_, err := evePriv.Repudiate(sig)
+		assert.Equal(t, ErrSignerNotInRing, err)
+	})
+
+	t.Run("Repudiations are bound to the signature", func(t *testing.T) {
+		other, err := carolPriv.SignLinkable(nil, []byte("no they're not"), ringKeys, 2, scope)
+		assert.NoError(t, err, "SignLinkable()")
+
+		rep, err := bobPriv.Repudiate(other)
+		assert.NoError(t, err, "Repudiate()")
+		assert.Equal(t, ErrInvalidRepudiation, sig.VerifyRepudiation(message, rep))
+	})
+
+	t.Run("Rejects invalid signatures", func(t *testing.T) {
+		rep, err := bobPriv.Repudiate(sig)
+		assert.NoError(t, err, "Repudiate()")
+		assert.Equal(t, ErrInvalidSignature, sig.VerifyRepudiation([]byte("the meetings are fine"), rep))
+
+		tampered := *sig
+		tampered.s = append([][]byte{sig.s[1]}, sig.s[1:]...)
+		assert.Equal(t, ErrInvalidSignature, tampered.VerifyRepudiation(message, rep))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		rep, err := carolPriv.Repudiate(sig)
+		assert.NoError(t, err, "Repudiate()")
+
+		encoded, err := rep.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &Repudiation{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.Equal(t, carolPub, decoded.Member())
+		assert.NoError(t, sig.VerifyRepudiation(message, decoded))
+	})
+}
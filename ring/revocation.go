@@ -0,0 +1,291 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrKeyRevoked is returned when a signature's ring contains a key that
+	// was revoked when the signature was produced.
+	ErrKeyRevoked = errors.New("the ring contains a revoked key")
+
+	// ErrInvalidRevocationList is returned when a revocation list is
+	// malformed or isn't signed by the expected issuer.
+	ErrInvalidRevocationList = errors.New("invalid revocation list")
+
+	// ErrStaleRevocationList is returned when a revocation list is older
+	// than the last one accepted from the same issuer.
+	ErrStaleRevocationList = errors.New("the revocation list is older than the last one accepted")
+)
+
+// revocationDomain separates revocation list hashes from other hashes.
+const revocationDomain = "ring-signatures/revocation/v2"
+
+// Revocation revokes a key from an effective date.
+// Signatures produced before that date are still accepted, so that
+// revoking a stolen key doesn't invalidate the member's past signatures.
+type Revocation struct {
+	Fingerprint Fingerprint
+	// Effective is the time from which the key is revoked.
+	Effective time.Time
+	// Reason is a human-readable explanation.
+	Reason string
+}
+
+// RevocationList is a list of revoked keys signed by an issuer.
+// Times are stored with a one-second precision.
+// The zero value is an empty unsigned list ready to use.
+type RevocationList struct {
+	// Sequence is incremented each time the list is signed, so that
+	// verifiers can detect an older list being replayed (see CheckNewer).
+	Sequence uint64
+	// Issued is the time the list was signed.
+	Issued      time.Time
+	Revocations []Revocation
+
+	issuer PublicKey
+	r      []byte
+	s      []byte
+}
+
+// Revoke revokes a key from the effective date.
+// If the key was already revoked, its revocation is replaced.
+// The list needs to be signed again afterwards.
+func (rl *RevocationList) Revoke(f Fingerprint, effective time.Time, reason string) {
+	rl.r, rl.s = nil, nil
+	revocation := Revocation{Fingerprint: f, Effective: unixTime(timestampOf(effective)), Reason: reason}
+	for i := range rl.Revocations {
+		if rl.Revocations[i].Fingerprint == f {
+			rl.Revocations[i] = revocation
+			return
+		}
+	}
+
+	rl.Revocations = append(rl.Revocations, revocation)
+}
+
+// Lookup returns the revocation of a key, if any.
+func (rl *RevocationList) Lookup(f Fingerprint) (Revocation, bool) {
+	for _, r := range rl.Revocations {
+		if r.Fingerprint == f {
+			return r, true
+		}
+	}
+
+	return Revocation{}, false
+}
+
+// Issuer returns the public key that signed the list, or nil if the list
+// isn't signed.
+func (rl *RevocationList) Issuer() PublicKey {
+	return rl.issuer
+}
+
+// bind serializes the signed content of the list.
+func (rl *RevocationList) bind(issuer PublicKey) []byte {
+	var b []byte
+	b = appendBytes(b, []byte(revocationDomain))
+	b = appendBytes(b, issuer)
+	b = appendUint64(b, rl.Sequence)
+	b = appendUint64(b, uint64(timestampOf(rl.Issued)))
+	b = appendUint64(b, uint64(len(rl.Revocations)))
+	for _, r := range rl.Revocations {
+		b = appendBytes(b, r.Fingerprint[:])
+		b = appendUint64(b, uint64(timestampOf(r.Effective)))
+		b = appendBytes(b, []byte(r.Reason))
+	}
+
+	return b
+}
+
+// Sign signs the list with the issuer's private key.
+// It increments the list's sequence number.
+// If the list has no issue time, the current time is used.
+// If no random generator is provided, Sign will use
+// go's default cryptographic random generator.
+func (rl *RevocationList) Sign(rand io.Reader, sk PrivateKey) error {
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	if rl.Issued.IsZero() {
+		rl.Issued = time.Now()
+	}
+
+	rl.Issued = unixTime(timestampOf(rl.Issued))
+
+	issuer, ok := mixedECPrivateKey(sk, elliptic.P384())
+	if !ok || issuer.D.Sign() == 0 {
+		return ErrInvalidPrivateKey
+	}
+
+	rl.Sequence++
+
+	pk := sk.Public()
+	r, s, err := ecdsa.Sign(rand, issuer, hash(rl.bind(pk)))
+	if err != nil {
+		rl.Sequence--
+		return errors.WithStack(err)
+	}
+
+	rl.issuer, rl.r, rl.s = pk, r.Bytes(), s.Bytes()
+	return nil
+}
+
+// Verify checks that the list is signed by the expected issuer.
+// Revocation lists should only be trusted after being verified:
+// anyone can produce a list signed by their own key.
+func (rl *RevocationList) Verify(issuer PublicKey) error {
+	if rl == nil || len(rl.r) == 0 || len(rl.s) == 0 || !bytes.Equal(rl.issuer, issuer) {
+		return ErrInvalidRevocationList
+	}
+
+	curve := elliptic.P384()
+	x, y := elliptic.Unmarshal(curve, issuer)
+	if x == nil {
+		return ErrInvalidPublicKey
+	}
+
+	pub := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	r, s := new(big.Int).SetBytes(rl.r), new(big.Int).SetBytes(rl.s)
+	if !ecdsa.Verify(pub, hash(rl.bind(issuer)), r, s) {
+		return ErrInvalidRevocationList
+	}
+
+	return nil
+}
+
+// CheckNewer returns ErrStaleRevocationList if the list is older than the
+// last list accepted from the same issuer, which may be nil.
+// An attacker can replay an older list that is still correctly signed
+// to hide recent revocations: verifiers should keep the last list they
+// accepted and call CheckNewer on every list they receive afterwards.
+// Both lists must have been verified beforehand.
+func (rl *RevocationList) CheckNewer(last *RevocationList) error {
+	if last == nil {
+		return nil
+	}
+
+	if rl.Sequence < last.Sequence || rl.Issued.Before(last.Issued) {
+		return ErrStaleRevocationList
+	}
+
+	return nil
+}
+
+// Check returns an error if one of the ring keys was revoked at the given
+// time.
+func (rl *RevocationList) Check(ringKeys []PublicKey, at time.Time) error {
+	for i, pk := range ringKeys {
+		f, err := pk.Fingerprint()
+		if err != nil {
+			return errors.Wrapf(err, "ring member %d", i)
+		}
+
+		if r, ok := rl.Lookup(f); ok && !at.Before(r.Effective) {
+			return errors.Wrapf(ErrKeyRevoked, "ring member %d (%s)", i, f)
+		}
+	}
+
+	return nil
+}
+
+// VerifyWithRevocations verifies the validity of the message signature and
+// rejects it if a ring member was revoked when it was produced.
+// The revocation list must have been verified beforehand.
+//
+// The signature is dated with its signed timestamp, or with the current
+// time if it has none, like in VerifyWithDescriptors.
+// Beware that the timestamp is chosen by the signer: whoever holds a stolen
+// key can backdate their signatures. Verifiers that recorded when they
+// received a signature should rather call Check with that time.
+func (sig *Signature) VerifyWithRevocations(message []byte, rl *RevocationList) error {
+	if !sig.Verify(message) {
+		return ErrInvalidSignature
+	}
+
+	return rl.Check(sig.ring, sig.signedAt())
+}
+
+// revocationJSON is the JSON representation of a revocation.
+type revocationJSON struct {
+	Fingerprint string    `json:"fingerprint"`
+	Effective   time.Time `json:"effective"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// revocationListJSON is the JSON representation of a revocation list.
+type revocationListJSON struct {
+	Issuer      []byte           `json:"issuer"`
+	Sequence    uint64           `json:"sequence"`
+	Issued      time.Time        `json:"issued"`
+	Revocations []revocationJSON `json:"revocations"`
+	R           []byte           `json:"r"`
+	S           []byte           `json:"s"`
+}
+
+// Marshal encodes the revocation list to indented JSON,
+// suitable for distributing it as a file.
+func (rl *RevocationList) Marshal() ([]byte, error) {
+	v := revocationListJSON{
+		Issuer:      rl.issuer,
+		Sequence:    rl.Sequence,
+		Issued:      unixTime(timestampOf(rl.Issued)),
+		Revocations: make([]revocationJSON, len(rl.Revocations)),
+		R:           rl.r,
+		S:           rl.s,
+	}
+
+	for i, r := range rl.Revocations {
+		v.Revocations[i] = revocationJSON{
+			Fingerprint: hex.EncodeToString(r.Fingerprint[:]),
+			Effective:   unixTime(timestampOf(r.Effective)),
+			Reason:      r.Reason,
+		}
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	return b, errors.WithStack(err)
+}
+
+// Unmarshal decodes a revocation list.
+// It doesn't verify the list's signature.
+func (rl *RevocationList) Unmarshal(data []byte) error {
+	var v revocationListJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Wrap(ErrInvalidRevocationList, err.Error())
+	}
+
+	revocations := make([]Revocation, len(v.Revocations))
+	for i, r := range v.Revocations {
+		f, err := ParseFingerprint(r.Fingerprint)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidRevocationList, "revocation %d", i)
+		}
+
+		revocations[i] = Revocation{
+			Fingerprint: f,
+			Effective:   unixTime(timestampOf(r.Effective)),
+			Reason:      r.Reason,
+		}
+	}
+
+	rl.Sequence = v.Sequence
+	rl.Issued = unixTime(timestampOf(v.Issued))
+	rl.Revocations = revocations
+	rl.issuer = PublicKey(v.Issuer)
+	rl.r = v.R
+	rl.s = v.S
+
+	return nil
+}
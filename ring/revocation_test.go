@@ -0,0 +1,133 @@
+package ring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocationList(t *testing.T) {
+	alicePub, alicePriv := Generate(nil)
+	bobPub, _ := Generate(nil)
+	carolPub, _ := Generate(nil)
+	issuerPub, issuerPriv := Generate(nil)
+	ringKeys := []PublicKey{alicePub, bobPub}
+
+	bobFingerprint, err := bobPub.Fingerprint()
+	assert.NoError(t, err, "Fingerprint()")
+
+	stolen := time.Unix(1538000000, 0).UTC()
+	rl := &RevocationList{}
+	rl.Revoke(bobFingerprint, stolen, "laptop stolen")
+	assert.NoError(t, rl.Sign(nil, issuerPriv), "Sign()")
+
+	message := []byte("signed before the theft")
+
+	t.Run("Verifies the issuer's signature", func(t *testing.T) {
+		assert.NoError(t, rl.Verify(issuerPub))
+		assert.Equal(t, issuerPub, rl.Issuer())
+		assert.Equal(t, ErrInvalidRevocationList, rl.Verify(alicePub))
+		assert.Equal(t, ErrInvalidRevocationList, (&RevocationList{}).Verify(issuerPub))
+
+		tampered := *rl
+		tampered.Revocations = []Revocation{{Fingerprint: bobFingerprint, Effective: stolen.Add(time.Hour)}}
+		assert.Equal(t, ErrInvalidRevocationList, tampered.Verify(issuerPub))
+
+		tampered = *rl
+		tampered.Issued = rl.Issued.Add(time.Second)
+		assert.Equal(t, ErrInvalidRevocationList, tampered.Verify(issuerPub))
+	})
+
+	t.Run("Replaces existing revocations", func(t *testing.T) {
+		updated := &RevocationList{}
+		updated.Revoke(bobFingerprint, stolen, "")
+		updated.Revoke(bobFingerprint, stolen.Add(-time.Hour), "stolen earlier")
+		assert.Len(t, updated.Revocations, 1)
+
+		r, ok := updated.Lookup(bobFingerprint)
+		assert.True(t, ok)
+		assert.Equal(t, stolen.Add(-time.Hour), r.Effective)
+		assert.Equal(t, "stolen earlier", r.Reason)
+
+		assert.NoError(t, updated.Sign(nil, issuerPriv), "Sign()")
+		updated.Revoke(bobFingerprint, stolen, "")
+		assert.Equal(t, ErrInvalidRevocationList, updated.Verify(issuerPub))
+	})
+
+	t.Run("Rejects replayed lists", func(t *testing.T) {
+		old := &RevocationList{Issued: stolen}
+		assert.NoError(t, old.Sign(nil, issuerPriv), "Sign()")
+		assert.Equal(t, uint64(1), old.Sequence)
+
+		newer := *old
+		newer.Revoke(bobFingerprint, stolen, "laptop stolen")
+		assert.NoError(t, newer.Sign(nil, issuerPriv), "Sign()")
+		assert.Equal(t, uint64(2), newer.Sequence)
+		assert.NoError(t, newer.Verify(issuerPub))
+
+		assert.NoError(t, old.CheckNewer(nil))
+		assert.NoError(t, newer.CheckNewer(old))
+		assert.NoError(t, newer.CheckNewer(&newer))
+		assert.Equal(t, ErrStaleRevocationList, old.CheckNewer(&newer))
+
+		backdated := &RevocationList{Sequence: newer.Sequence, Issued: stolen.Add(-time.Hour)}
+		assert.NoError(t, backdated.Sign(nil, issuerPriv), "Sign()")
+		assert.Equal(t, ErrStaleRevocationList, backdated.CheckNewer(&newer))
+
+		tampered := *old
+		tampered.Sequence = newer.Sequence + 1
+		assert.Equal(t, ErrInvalidRevocationList, tampered.Verify(issuerPub))
+	})
+
+	t.Run("Checks rings at a given time", func(t *testing.T) {
+		assert.NoError(t, rl.Check(ringKeys, stolen.Add(-time.Second)))
+		assert.Equal(t, ErrKeyRevoked, errors.Cause(rl.Check(ringKeys, stolen)))
+		assert.Equal(t, ErrKeyRevoked, errors.Cause(rl.Check(ringKeys, stolen.Add(time.Hour))))
+		assert.NoError(t, rl.Check([]PublicKey{alicePub, carolPub}, stolen.Add(time.Hour)))
+		assert.Equal(t, ErrInvalidPublicKey, errors.Cause(rl.Check([]PublicKey{PublicKey("key")}, stolen)))
+	})
+
+	t.Run("Accepts signatures produced before the revocation", func(t *testing.T) {
+		sig, err := alicePriv.SignWithAttributes(nil, message, ringKeys, 0, Attributes{Timestamp: stolen.Add(-time.Minute)})
+		assert.NoError(t, err, "SignWithAttributes()")
+		assert.NoError(t, sig.VerifyWithRevocations(message, rl))
+		assert.Equal(t, ErrInvalidSignature, sig.VerifyWithRevocations([]byte("another message"), rl))
+	})
+
+	t.Run("Rejects signatures produced after the revocation", func(t *testing.T) {
+		sig, err := alicePriv.SignWithAttributes(nil, message, ringKeys, 0, Attributes{Timestamp: stolen})
+		assert.NoError(t, err, "SignWithAttributes()")
+		assert.Equal(t, ErrKeyRevoked, errors.Cause(sig.VerifyWithRevocations(message, rl)))
+	})
+
+	t.Run("Checks undated signatures at the current time", func(t *testing.T) {
+		sig, err := alicePriv.Sign(nil, message, ringKeys, 0)
+		assert.NoError(t, err, "Sign()")
+		assert.Equal(t, ErrKeyRevoked, errors.Cause(sig.VerifyWithRevocations(message, rl)))
+
+		upcoming := &RevocationList{}
+		upcoming.Revoke(bobFingerprint, time.Now().Add(time.Hour), "leaving next week")
+		assert.NoError(t, sig.VerifyWithRevocations(message, upcoming))
+
+		sig, err = alicePriv.Sign(nil, message, []PublicKey{alicePub, carolPub}, 0)
+		assert.NoError(t, err, "Sign()")
+		assert.NoError(t, sig.VerifyWithRevocations(message, rl))
+	})
+
+	t.Run("Marshals and unmarshals", func(t *testing.T) {
+		b, err := rl.Marshal()
+		assert.NoError(t, err, "Marshal()")
+
+		decoded := &RevocationList{}
+		assert.NoError(t, decoded.Unmarshal(b), "Unmarshal()")
+		assert.NoError(t, decoded.Verify(issuerPub))
+		assert.Equal(t, rl.Revocations, decoded.Revocations)
+		assert.Equal(t, rl.Issued, decoded.Issued)
+		assert.Equal(t, rl.Sequence, decoded.Sequence)
+
+		err = decoded.Unmarshal([]byte(`{"revocations": [{"fingerprint": "abcd"}]}`))
+		assert.Equal(t, ErrInvalidRevocationList, errors.Cause(err))
+	})
+}
@@ -21,6 +21,13 @@ var (
 
 	// ErrRingTooSmall is returned when the ring contains less than two participants.
 	ErrRingTooSmall = errors.New("the ring is too small: you need at least two participants")
+
+	// ErrSignerNotInRing is returned when the signer's public key is not in the ring.
+	ErrSignerNotInRing = errors.New("the signer's public key is not in the ring")
+
+	// ErrSignerMismatch is returned when the private key doesn't match the
+	// public key at the signer index.
+	ErrSignerMismatch = errors.New("the private key does not match the public key at the signer index")
 )
 
 // Generate generates a new public-private key pair.
@@ -46,9 +53,26 @@ func Generate(rand io.Reader) (PublicKey, PrivateKey) {
 
 // Signature is the struct representing a ring signature.
 type Signature struct {
-	ring []PublicKey
-	e    []byte
-	s    [][]byte
+	ring  []PublicKey
+	e     []byte
+	s     [][]byte
+	attrs *Attributes
+	claim *claimCommitment
+}
+
+// Ring returns the public keys of the ring that produced the signature.
+func (sig *Signature) Ring() []PublicKey {
+	return sig.ring
+}
+
+// Members returns the public keys of the ring that produced the signature.
+func (sig *Signature) Members() [][]byte {
+	members := make([][]byte, len(sig.ring))
+	for i, pk := range sig.ring {
+		members[i] = pk
+	}
+
+	return members
 }
 
 // Signing algorithm (Schnorr Ring Signature):
@@ -64,13 +88,54 @@ type Signature struct {
 //		* Compute e(i+1 % R) = H(m || s(i)*G + e(i)*P(i))
 //	* Compute s(r) = k - e(r)*x(r)
 //	* Output signature: (P(0),...,P(1),e(0),s(0),...,s(r))
+//
+// Claimable signatures add a commitment to the signer to every challenge
+// (see SignClaimable).
+
+// SignerIndex finds the position of the signer in the ring.
+func (sk PrivateKey) SignerIndex(ringKeys []PublicKey) (int, error) {
+	pk := sk.Public()
+	for i, k := range ringKeys {
+		if bytes.Equal(k, pk) {
+			return i, nil
+		}
+	}
+
+	return 0, ErrSignerNotInRing
+}
+
+// SignAuto creates a ring signature for the given message,
+// finding the position of the signer in the ring automatically.
+func (sk PrivateKey) SignAuto(rand io.Reader, message []byte, ringKeys []PublicKey) (*Signature, error) {
+	signerIndex, err := sk.SignerIndex(ringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return sk.Sign(rand, message, ringKeys, signerIndex)
+}
 
 // Sign creates a ring signature for the given message.
+// The public key at the signer index must match the private key.
 func (sk PrivateKey) Sign(
 	rand io.Reader,
 	message []byte,
 	ringKeys []PublicKey,
 	signerIndex int,
+) (*Signature, error) {
+	return sk.sign(rand, message, ringKeys, signerIndex, nil, nil)
+}
+
+// sign creates a ring signature for the given message and attributes.
+// When a claim commitment is given, it is tied to the signer's key so that
+// they can later claim the signature.
+func (sk PrivateKey) sign(
+	rand io.Reader,
+	message []byte,
+	ringKeys []PublicKey,
+	signerIndex int,
+	attrs *Attributes,
+	claim *claimCommitment,
 ) (*Signature, error) {
 	if len(message) == 0 {
 		return nil, ErrEmptyMessage
@@ -84,6 +149,23 @@ func (sk PrivateKey) Sign(
 		return nil, ErrRingTooSmall
 	}
 
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if !bytes.Equal(sk.Public(), ringKeys[signerIndex]) {
+		return nil, ErrSignerMismatch
+	}
+
+	// Every ring member must have a P-384 key. Mixed rings are signed
+	// with SignMixed.
+	curve := elliptic.P384()
+	for _, pk := range ringKeys {
+		if x, _ := elliptic.Unmarshal(curve, pk); x == nil {
+			return nil, ErrUnsupportedKeyType
+		}
+	}
+
 	if rand == nil {
 		rand = crand.Reader
 	}
@@ -91,7 +173,10 @@ func (sk PrivateKey) Sign(
 	es := make([][]byte, len(ringKeys))
 	ss := make([][]byte, len(ringKeys))
 
-	curve := elliptic.P384()
+	// The attributes and claim commitment are bound to the message in the
+	// challenge hash.
+	message = claim.bind(attrs.bind(message))
+
 	r := len(ringKeys)
 
 	// Initialize the ring.
@@ -102,7 +187,7 @@ func (sk PrivateKey) Sign(
 	}
 
 	x, y := curve.ScalarBaseMult(k)
-	es[(signerIndex+1)%r] = hash(append(message, elliptic.Marshal(curve, x, y)...))
+	es[(signerIndex+1)%r] = hash(append(append(message, elliptic.Marshal(curve, x, y)...), claim.noncePoint(curve, k)...))
 
 	// Iterate over the whole ring.
 
@@ -118,7 +203,13 @@ func (sk PrivateKey) Sign(
 		px, py := elliptic.Unmarshal(curve, ringKeys[i])
 		x2, y2 := curve.ScalarMult(px, py, es[i])
 		x, y = curve.Add(x1, y1, x2, y2)
-		es[(i+1)%r] = hash(append(message, elliptic.Marshal(curve, x, y)...))
+
+		claimPoint, err := claim.ringPoint(curve, ss[i], es[i])
+		if err != nil {
+			return nil, err
+		}
+
+		es[(i+1)%r] = hash(append(append(message, elliptic.Marshal(curve, x, y)...), claimPoint...))
 	}
 
 	// Close the ring.
@@ -141,19 +232,22 @@ func (sk PrivateKey) Sign(
 		// We need to take it modulo N otherwise it's easy to figure out who the signer is,
 		// you just have to look at the only value that is bigger than N in the s array.
 		_, valS = new(big.Int).DivMod(valS, curve.Params().N, new(big.Int))
+	}
 
-		if valS.Sign() == 0 {
-			// Tough luck...
-			return nil, errors.New("could not produce ring signature")
-		}
+	// A zero scalar has no canonical encoding.
+	if valS.Sign() == 0 {
+		// Tough luck...
+		return nil, errors.New("could not produce ring signature")
 	}
 
 	ss[signerIndex] = valS.Bytes()
 
 	sig := &Signature{
-		ring: ringKeys,
-		e:    es[0],
-		s:    ss,
+		ring:  ringKeys,
+		e:     es[0],
+		s:     ss,
+		attrs: attrs,
+		claim: claim,
 	}
 
 	return sig, nil
@@ -207,6 +301,7 @@ func (sig *Signature) Verify(message []byte) bool {
 	}
 
 	curve := elliptic.P384()
+	message = sig.claim.bind(sig.attrs.bind(message))
 
 	e := make([]byte, len(sig.e))
 	copy(e, sig.e)
@@ -214,11 +309,28 @@ func (sig *Signature) Verify(message []byte) bool {
 	for i := 0; i < len(sig.ring); i++ {
 		x1, y1 := curve.ScalarBaseMult(sig.s[i])
 		px, py := elliptic.Unmarshal(curve, sig.ring[i])
+		if px == nil {
+			return false
+		}
+
 		x2, y2 := curve.ScalarMult(px, py, e)
 
 		x, y := curve.Add(x1, y1, x2, y2)
-		e = hash(append(message, elliptic.Marshal(curve, x, y)...))
+
+		claimPoint, err := sig.claim.ringPoint(curve, sig.s[i], e)
+		if err != nil {
+			return false
+		}
+
+		e = hash(append(append(message, elliptic.Marshal(curve, x, y)...), claimPoint...))
 	}
 
 	return bytes.Equal(e, sig.e)
 }
+
+// VerifyStrict verifies the validity of the message signature and rejects
+// scalars that aren't canonically encoded (see DecodeOptions.StrictEncoding).
+// Signatures produced by Sign always pass this check.
+func (sig *Signature) VerifyStrict(message []byte) bool {
+	return sig != nil && checkScalars(sig.s) == nil && sig.Verify(message)
+}
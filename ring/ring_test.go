@@ -153,6 +153,20 @@ func TestSign(t *testing.T) {
 		assert.EqualError(t, err, ErrRingTooSmall.Error())
 	})
 
+	t.Run("Rejects large ring", func(t *testing.T) {
+		ringKeys := make([]PublicKey, MaxRingSize+1)
+		for i := range ringKeys {
+			ringKeys[i] = bobPub
+		}
+
+		ringKeys[0] = alicePub
+		_, err := alicePriv.Sign(nil, []byte("hello"), ringKeys, 0)
+		assert.EqualError(t, err, ErrRingTooLarge.Error())
+
+		_, err = alicePriv.SignLinkable(nil, []byte("hello"), ringKeys, 0, []byte("scope"))
+		assert.EqualError(t, err, ErrRingTooLarge.Error())
+	})
+
 	t.Run("Rejects invalid index", func(t *testing.T) {
 		_, err := alicePriv.Sign(nil, []byte("hello"), []PublicKey{alicePub, bobPub}, -1)
 		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
@@ -161,6 +175,40 @@ func TestSign(t *testing.T) {
 		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
 	})
 
+	t.Run("Rejects mismatched signer index", func(t *testing.T) {
+		_, err := alicePriv.Sign(nil, []byte("hello"), []PublicKey{alicePub, bobPub}, 1)
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+	})
+
+	t.Run("Rejects keys that are not on P-384", func(t *testing.T) {
+		curve := elliptic.P256()
+		_, x, y, err := elliptic.GenerateKey(curve, crand.Reader)
+		assert.NoError(t, err, "elliptic.GenerateKey()")
+
+		p256 := PublicKey(elliptic.Marshal(curve, x, y))
+		_, err = alicePriv.Sign(nil, []byte("hello"), []PublicKey{alicePub, p256}, 0)
+		assert.EqualError(t, err, ErrUnsupportedKeyType.Error())
+	})
+
+	t.Run("Finds signer index", func(t *testing.T) {
+		i, err := carolPriv.SignerIndex([]PublicKey{alicePub, bobPub, carolPub})
+		assert.NoError(t, err, "SignerIndex()")
+		assert.Equal(t, 2, i)
+
+		_, err = carolPriv.SignerIndex([]PublicKey{alicePub, bobPub})
+		assert.EqualError(t, err, ErrSignerNotInRing.Error())
+	})
+
+	t.Run("Sign with automatic index", func(t *testing.T) {
+		message := []byte("Where am I?")
+		sig, err := bobPriv.SignAuto(nil, message, []PublicKey{alicePub, bobPub, carolPub})
+		assert.NoError(t, err, "SignAuto()")
+		assert.True(t, sig.Verify(message))
+
+		_, err = bobPriv.SignAuto(nil, message, []PublicKey{alicePub, carolPub})
+		assert.EqualError(t, err, ErrSignerNotInRing.Error())
+	})
+
 	t.Run("Sign without error", func(t *testing.T) {
 		ringKeys := []PublicKey{alicePub, bobPub, carolPub}
 		signers := []PrivateKey{alicePriv, bobPriv, carolPriv}
@@ -215,14 +263,6 @@ func TestVerify(t *testing.T) {
 		assert.True(t, sig.Verify(message))
 		assert.False(t, sig.Verify([]byte("not hidden very insecure")))
 	})
-
-	t.Run("Invalid signer index", func(t *testing.T) {
-		message := []byte("very secret much hidden")
-		sig, err := alicePriv.Sign(nil, message, []PublicKey{alicePub, bobPub}, 1)
-
-		assert.NoError(t, err)
-		assert.False(t, sig.Verify(message))
-	})
 }
 
 // GenerateKeys generates a set of keys for benchmarks.
@@ -0,0 +1,499 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrRSAKeyTooSmall is returned when a ring contains an RSA key that is
+	// too small to be safe.
+	ErrRSAKeyTooSmall = errors.New("RSA keys should be at least 2048 bits")
+
+	// ErrRSAKeyTooLarge is returned when a ring contains an RSA key that is
+	// too large to be verified in a reasonable time.
+	ErrRSAKeyTooLarge = errors.New("RSA keys should be at most 8192 bits")
+)
+
+const (
+	// rsaDomain separates RSA ring signature hashes from other hashes.
+	rsaDomain = "ring-signatures/rsa/v1"
+
+	// rsaMinBits is the minimum size of RSA keys.
+	rsaMinBits = 2048
+
+	// rsaMaxBits is the maximum size of RSA keys.
+	rsaMaxBits = 8192
+
+	// rsaExtraBits is the number of bits added to the largest modulus to
+	// build the common domain of the trapdoor permutations.
+	rsaExtraBits = 160
+
+	// feistelRounds is the number of rounds of the symmetric permutation.
+	feistelRounds = 4
+)
+
+// RSASignature is a ring signature built from RSA keys, as described by
+// Rivest, Shamir and Tauman in "How to leak a secret".
+type RSASignature struct {
+	ring []*rsa.PublicKey
+	v    []byte
+	x    [][]byte
+}
+
+// RSA signing algorithm (Rivest-Shamir-Tauman):
+//	* Let (P(1),...,P(R)) be the RSA public keys in the ring, with
+//	  trapdoor permutations f(i)(x) = x^e(i) mod n(i)
+//	* Let b be the size of the largest modulus plus 160 bits
+//	* Extend every f(i) to a permutation g(i) on b-bit strings:
+//		* Write x = q*n(i) + r with 0 <= r < n(i)
+//		* If (q+1)*n(i) <= 2^b, g(i)(x) = q*n(i) + f(i)(r), otherwise g(i)(x) = x
+//	* Let E(k) be a symmetric permutation on b-bit strings keyed with
+//	  k = H(P(1) || ... || P(R) || m)
+//	* The ring equation is C(k,v)(y(1),...,y(R)) = v, with the combining
+//	  function C(k,v)(y(1),...,y(R)) = E(k)(y(R) ^ E(k)(y(R-1) ^ ... E(k)(y(1) ^ v)))
+//	* Let s be the index of the actual signer
+//	* Randomly choose the glue value v and x(i) for i != s, and compute y(i) = g(i)(x(i))
+//	* Solve the ring equation for y(s) and compute x(s) = g(s)^-1(y(s))
+//	  with the signer's private key
+//	* Output signature: (P(1),...,P(R),v,x(1),...,x(R))
+//
+// E(k) is a 4-round Feistel network whose round function is HMAC-SHA256
+// in counter mode.
+
+// SignRSA creates a ring signature of the given message with RSA keys.
+// The public key at the signer index must match the private key.
+func SignRSA(
+	rand io.Reader,
+	message []byte,
+	ringKeys []*rsa.PublicKey,
+	signerIndex int,
+	sk *rsa.PrivateKey,
+) (*RSASignature, error) {
+	if len(message) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if signerIndex < 0 || len(ringKeys) <= signerIndex {
+		return nil, ErrInvalidSignerIndex
+	}
+
+	if len(ringKeys) < 2 {
+		return nil, ErrRingTooSmall
+	}
+
+	if len(ringKeys) > MaxRingSize {
+		return nil, ErrRingTooLarge
+	}
+
+	if sk == nil || !sameRSAKey(&sk.PublicKey, ringKeys[signerIndex]) {
+		return nil, ErrSignerMismatch
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	size, err := rsaDomainSize(ringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	k := rsaKey(ringKeys, message)
+	r := len(ringKeys)
+
+	v := make([]byte, size)
+	if _, err := io.ReadFull(rand, v); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	xs := make([][]byte, r)
+	ys := make([][]byte, r)
+	for i := 0; i < r; i++ {
+		if i == signerIndex {
+			continue
+		}
+
+		xs[i] = make([]byte, size)
+		if _, err := io.ReadFull(rand, xs[i]); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		ys[i] = rsaPermute(ringKeys[i], xs[i])
+	}
+
+	// Go forward from v to the signer.
+	z := v
+	for i := 0; i < signerIndex; i++ {
+		z = feistel(k, xorBytes(ys[i], z), false)
+	}
+
+	// Go backward from v to the signer.
+	w := v
+	for i := r - 1; i > signerIndex; i-- {
+		w = xorBytes(feistel(k, w, true), ys[i])
+	}
+
+	// E(k)(y(s) ^ z) = w
+	ys[signerIndex] = xorBytes(feistel(k, w, true), z)
+	xs[signerIndex], err = rsaInvert(rand, sk, ys[signerIndex])
+	if err != nil {
+		return nil, err
+	}
+
+	return &RSASignature{
+		ring: ringKeys,
+		v:    v,
+		x:    xs,
+	}, nil
+}
+
+// Verify verifies the validity of the message signature.
+// It does not detail why the signature validation failed.
+func (sig *RSASignature) Verify(message []byte) bool {
+	if sig == nil || len(sig.ring) < 2 || len(sig.x) != len(sig.ring) {
+		return false
+	}
+
+	size, err := rsaDomainSize(sig.ring)
+	if err != nil || len(sig.v) != size {
+		return false
+	}
+
+	k := rsaKey(sig.ring, message)
+	z := sig.v
+	for i, pk := range sig.ring {
+		if len(sig.x[i]) != size {
+			return false
+		}
+
+		z = feistel(k, xorBytes(rsaPermute(pk, sig.x[i]), z), false)
+	}
+
+	return bytes.Equal(z, sig.v)
+}
+
+// Ring returns the public keys of the ring that produced the signature.
+func (sig *RSASignature) Ring() []*rsa.PublicKey {
+	return sig.ring
+}
+
+// sameRSAKey returns true if both public keys are equal.
+func sameRSAKey(a, b *rsa.PublicKey) bool {
+	return a != nil && b != nil && a.E == b.E && a.N.Cmp(b.N) == 0
+}
+
+// rsaDomainSize returns the size in bytes of the common domain of the
+// ring's trapdoor permutations. It is even so that it can be split in
+// two halves by the Feistel network.
+func rsaDomainSize(ringKeys []*rsa.PublicKey) (int, error) {
+	maxBits := 0
+	for _, pk := range ringKeys {
+		if err := checkRSAKey(pk); err != nil {
+			return 0, err
+		}
+
+		if pk.N.BitLen() > maxBits {
+			maxBits = pk.N.BitLen()
+		}
+	}
+
+	size := (maxBits + rsaExtraBits + 7) / 8
+	return size + size%2, nil
+}
+
+// checkRSAKey checks that an RSA public key can be used in a ring.
+func checkRSAKey(pk *rsa.PublicKey) error {
+	if pk == nil || pk.N == nil || pk.E < 3 {
+		return ErrInvalidPublicKey
+	}
+
+	if pk.N.BitLen() < rsaMinBits {
+		return ErrRSAKeyTooSmall
+	}
+
+	if pk.N.BitLen() > rsaMaxBits {
+		return ErrRSAKeyTooLarge
+	}
+
+	return nil
+}
+
+// rsaKey derives the key of the symmetric permutation from the ring and
+// the message.
+func rsaKey(ringKeys []*rsa.PublicKey, message []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(rsaDomain))
+	for _, pk := range ringKeys {
+		writeLengthPrefixed(h, x509.MarshalPKCS1PublicKey(pk))
+	}
+
+	writeLengthPrefixed(h, message)
+	return h.Sum(nil)
+}
+
+// rsaPermute computes the extended trapdoor permutation g(x) of the key.
+func rsaPermute(pk *rsa.PublicKey, x []byte) []byte {
+	e := big.NewInt(int64(pk.E))
+	y, _ := rsaExtend(pk, x, func(r *big.Int) (*big.Int, error) {
+		return r.Exp(r, e, pk.N), nil
+	})
+
+	return y
+}
+
+// rsaInvert computes the inverse of the extended trapdoor permutation with
+// the private key.
+func rsaInvert(rand io.Reader, sk *rsa.PrivateKey, y []byte) ([]byte, error) {
+	return rsaExtend(&sk.PublicKey, y, func(r *big.Int) (*big.Int, error) {
+		return rsaDecrypt(rand, sk, r)
+	})
+}
+
+// rsaExtend extends an RSA permutation of [0:N-1] to byte strings of any
+// size larger than the modulus: x = q*N + r is mapped to q*N + f(r), except
+// for the last incomplete block which is left unchanged.
+func rsaExtend(pk *rsa.PublicKey, x []byte, f func(*big.Int) (*big.Int, error)) ([]byte, error) {
+	m := new(big.Int).SetBytes(x)
+	q, r := new(big.Int).DivMod(m, pk.N, new(big.Int))
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(8*len(x)))
+	upper := new(big.Int).Add(q, big.NewInt(1))
+	upper.Mul(upper, pk.N)
+	if upper.Cmp(limit) > 0 {
+		return x, nil
+	}
+
+	r, err := f(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Mul(q, pk.N)
+	m.Add(m, r)
+	return leftPad(m.Bytes(), len(x)), nil
+}
+
+// rsaDecrypt computes c^d mod N with the private key.
+// The input is blinded by a random factor so that the time of the
+// operation doesn't leak information about the private key.
+func rsaDecrypt(rand io.Reader, sk *rsa.PrivateKey, c *big.Int) (*big.Int, error) {
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	n := sk.N
+	var blind, unblind *big.Int
+	for unblind == nil {
+		var err error
+		blind, err = crand.Int(rand, n)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if blind.Sign() != 0 {
+			unblind = new(big.Int).ModInverse(blind, n)
+		}
+	}
+
+	// (c * blind^e)^d = c^d * blind
+	e := big.NewInt(int64(sk.E))
+	m := new(big.Int).Exp(blind, e, n)
+	m.Mul(m, c)
+	m.Mod(m, n)
+
+	// The key is shared with the caller: it is only read, never
+	// precomputed here, so that it can be used concurrently.
+	if len(sk.Primes) == 2 && sk.Precomputed.Dp != nil && sk.Precomputed.Dq != nil && sk.Precomputed.Qinv != nil {
+		// Chinese remainder theorem:
+		// m = m2 + q*(qInv*(m1 - m2) mod p)
+		p, q := sk.Primes[0], sk.Primes[1]
+		m1 := new(big.Int).Exp(m, sk.Precomputed.Dp, p)
+		m2 := new(big.Int).Exp(m, sk.Precomputed.Dq, q)
+		m1.Sub(m1, m2)
+		m1.Mul(m1, sk.Precomputed.Qinv)
+		m1.Mod(m1, p)
+		m1.Mul(m1, q)
+		m = m1.Add(m1, m2)
+	} else {
+		m.Exp(m, sk.D, n)
+	}
+
+	m.Mul(m, unblind)
+	m.Mod(m, n)
+
+	// A faulty computation would leak the factors of N: check the result
+	// before releasing it.
+	if new(big.Int).Exp(m, e, n).Cmp(new(big.Int).Mod(c, n)) != 0 {
+		return nil, errors.New("RSA private key operation failed")
+	}
+
+	return m, nil
+}
+
+// leftPad pads b with zeroes to the given size.
+func leftPad(b []byte, size int) []byte {
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
+
+// xorBytes xors two byte slices of the same size.
+func xorBytes(a, b []byte) []byte {
+	res := make([]byte, len(a))
+	for i := range a {
+		res[i] = a[i] ^ b[i]
+	}
+
+	return res
+}
+
+// feistel applies the keyed permutation E(k) to the input, or its inverse.
+func feistel(k, input []byte, inverse bool) []byte {
+	half := len(input) / 2
+	left := append([]byte{}, input[:half]...)
+	right := append([]byte{}, input[half:]...)
+
+	if !inverse {
+		for round := 0; round < feistelRounds; round++ {
+			left, right = right, xorBytes(left, feistelRound(k, round, right))
+		}
+	} else {
+		for round := feistelRounds - 1; round >= 0; round-- {
+			left, right = xorBytes(right, feistelRound(k, round, left)), left
+		}
+	}
+
+	return append(left, right...)
+}
+
+// feistelRound computes the round function of the Feistel network.
+func feistelRound(k []byte, round int, data []byte) []byte {
+	out := make([]byte, 0, len(data)+sha256.Size)
+	for counter := uint32(0); len(out) < len(data); counter++ {
+		mac := hmac.New(sha256.New, k)
+		binary.Write(mac, binary.BigEndian, uint32(round))
+		binary.Write(mac, binary.BigEndian, counter)
+		mac.Write(data)
+		out = mac.Sum(out)
+	}
+
+	return out[:len(data)]
+}
+
+// Marshal marshals an RSA signature to a byte representation.
+// Public keys are encoded in PKCS #1 form.
+func (sig *RSASignature) Marshal() ([]byte, error) {
+	keys := make([][]byte, len(sig.ring))
+	for i, pk := range sig.ring {
+		keys[i] = x509.MarshalPKCS1PublicKey(pk)
+	}
+
+	return json.Marshal(struct {
+		R [][]byte
+		V []byte
+		X [][]byte
+	}{
+		R: keys,
+		V: sig.v,
+		X: sig.x,
+	})
+}
+
+// rsaDecodeOptions are the default options of RSA signatures, whose ring
+// members take up to two moduli.
+var rsaDecodeOptions = defaultOptionsFor(rsaMaxBits/4 + 64)
+
+// Unmarshal unmarshals an RSA signature from its byte representation.
+// It rejects signatures exceeding the default limits.
+func (sig *RSASignature) Unmarshal(data []byte) error {
+	return sig.UnmarshalWithOptions(data, rsaDecodeOptions)
+}
+
+// UnmarshalWithOptions unmarshals an RSA signature from its byte
+// representation and checks it against the given limits.
+// Keys larger than 8192 bits are always rejected.
+func (sig *RSASignature) UnmarshalWithOptions(data []byte, opts DecodeOptions) error {
+	unmarshalled := struct {
+		R [][]byte
+		V []byte
+		X [][]byte
+	}{}
+	err := opts.unmarshalJSON(data, &unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.checkRingSize(len(unmarshalled.R)); err != nil {
+		return err
+	}
+
+	if err := opts.checkResponses(len(unmarshalled.R), unmarshalled.X); err != nil {
+		return err
+	}
+
+	ringKeys := make([]*rsa.PublicKey, len(unmarshalled.R))
+	for i, k := range unmarshalled.R {
+		pk, err := x509.ParsePKCS1PublicKey(k)
+		if err != nil {
+			return ErrInvalidPublicKey
+		}
+
+		if err := checkRSAKey(pk); err != nil {
+			return err
+		}
+
+		ringKeys[i] = pk
+	}
+
+	sig.ring = ringKeys
+	sig.v = unmarshalled.V
+	sig.x = unmarshalled.X
+
+	return opts.checkEncoding(data, sig.Marshal)
+}
+
+// Encode encodes an RSA signature to a friendly string representation.
+func (sig *RSASignature) Encode() (string, error) {
+	b, err := sig.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes an RSA signature from its friendly string representation.
+// It rejects signatures exceeding the default limits.
+func (sig *RSASignature) Decode(data string) error {
+	return sig.DecodeWithOptions(data, rsaDecodeOptions)
+}
+
+// DecodeWithOptions decodes an RSA signature from its friendly string
+// representation and checks it against the given limits.
+func (sig *RSASignature) DecodeWithOptions(data string, opts DecodeOptions) error {
+	b, err := opts.decodeBase64(data)
+	if err != nil {
+		return err
+	}
+
+	if err := sig.UnmarshalWithOptions(b, opts); err != nil {
+		return err
+	}
+
+	return opts.checkEncoding([]byte(data), func() ([]byte, error) {
+		encoded, err := sig.Encode()
+		return []byte(encoded), err
+	})
+}
@@ -0,0 +1,115 @@
+package ring
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSA(t *testing.T) {
+	var privKeys []*rsa.PrivateKey
+	var ringKeys []*rsa.PublicKey
+	for _, bits := range []int{2048, 2048, 3072} {
+		sk, err := rsa.GenerateKey(rand.Reader, bits)
+		assert.NoError(t, err, "rsa.GenerateKey()")
+		privKeys = append(privKeys, sk)
+		ringKeys = append(ringKeys, &sk.PublicKey)
+	}
+
+	message := []byte("how to leak a secret")
+
+	t.Run("Feistel permutation is invertible", func(t *testing.T) {
+		k := []byte("key")
+		input := make([]byte, 296)
+		_, err := rand.Read(input)
+		assert.NoError(t, err, "rand.Read()")
+
+		output := feistel(k, input, false)
+		assert.NotEqual(t, input, output)
+		assert.Equal(t, input, feistel(k, output, true))
+	})
+
+	t.Run("Trapdoor permutation is invertible", func(t *testing.T) {
+		x := make([]byte, 296)
+		_, err := rand.Read(x)
+		assert.NoError(t, err, "rand.Read()")
+
+		y := rsaPermute(ringKeys[0], x)
+		assert.Len(t, y, len(x))
+
+		inverted, err := rsaInvert(nil, privKeys[0], y)
+		assert.NoError(t, err, "rsaInvert()")
+		assert.Equal(t, x, inverted)
+
+		// Keys without precomputed values are used as is.
+		plain := &rsa.PrivateKey{PublicKey: privKeys[0].PublicKey, D: privKeys[0].D, Primes: privKeys[0].Primes}
+		inverted, err = rsaInvert(nil, plain, y)
+		assert.NoError(t, err, "rsaInvert()")
+		assert.Equal(t, x, inverted)
+		assert.Nil(t, plain.Precomputed.Dp)
+
+		// Faulty results are never released.
+		faulty := *privKeys[0]
+		faulty.Precomputed.Dp = new(big.Int).Add(privKeys[0].Precomputed.Dp, big.NewInt(1))
+		_, err = rsaInvert(nil, &faulty, y)
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := SignRSA(nil, nil, ringKeys, 0, privKeys[0])
+		assert.EqualError(t, err, ErrEmptyMessage.Error())
+
+		_, err = SignRSA(nil, message, ringKeys[:1], 0, privKeys[0])
+		assert.EqualError(t, err, ErrRingTooSmall.Error())
+
+		_, err = SignRSA(nil, message, ringKeys, 3, privKeys[0])
+		assert.EqualError(t, err, ErrInvalidSignerIndex.Error())
+
+		_, err = SignRSA(nil, message, ringKeys, 1, privKeys[0])
+		assert.EqualError(t, err, ErrSignerMismatch.Error())
+
+		small, err := rsa.GenerateKey(rand.Reader, 1024)
+		assert.NoError(t, err, "rsa.GenerateKey()")
+		_, err = SignRSA(nil, message, []*rsa.PublicKey{ringKeys[0], &small.PublicKey}, 0, privKeys[0])
+		assert.EqualError(t, err, ErrRSAKeyTooSmall.Error())
+	})
+
+	t.Run("Signs and verifies", func(t *testing.T) {
+		for i, sk := range privKeys {
+			sig, err := SignRSA(nil, message, ringKeys, i, sk)
+			assert.NoError(t, err, "SignRSA()")
+			assert.True(t, sig.Verify(message))
+			assert.False(t, sig.Verify([]byte("how to keep a secret")))
+			assert.Equal(t, ringKeys, sig.Ring())
+		}
+	})
+
+	t.Run("Rejects tampered signatures", func(t *testing.T) {
+		sig, err := SignRSA(nil, message, ringKeys, 1, privKeys[1])
+		assert.NoError(t, err, "SignRSA()")
+
+		sig.x[0][10] ^= 1
+		assert.False(t, sig.Verify(message))
+
+		sig, err = SignRSA(nil, message, ringKeys, 1, privKeys[1])
+		assert.NoError(t, err, "SignRSA()")
+
+		sig.ring = []*rsa.PublicKey{ringKeys[1], ringKeys[0], ringKeys[2]}
+		assert.False(t, sig.Verify(message))
+	})
+
+	t.Run("Encodes and decodes", func(t *testing.T) {
+		sig, err := SignRSA(nil, message, ringKeys, 2, privKeys[2])
+		assert.NoError(t, err, "SignRSA()")
+
+		encoded, err := sig.Encode()
+		assert.NoError(t, err, "Encode()")
+
+		decoded := &RSASignature{}
+		assert.NoError(t, decoded.Decode(encoded), "Decode()")
+		assert.True(t, decoded.Verify(message))
+	})
+}
@@ -0,0 +1,161 @@
+package ring
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownScheme is returned when looking up a scheme that doesn't exist.
+var ErrUnknownScheme = errors.New("unknown signature scheme")
+
+// Scheme is a ring signature scheme.
+// It lets applications handle elliptic curve and lattice keys the same way:
+// keys are exchanged as bytes in the scheme's encoding.
+type Scheme interface {
+	// Name returns the name of the scheme.
+	Name() string
+	// Generate generates a new public-private key pair.
+	Generate(rand io.Reader) (pk, sk []byte)
+	// Public returns the public key matching the private key.
+	Public(sk []byte) []byte
+	// Sign creates a ring signature for the given message.
+	// The private key's public key must be in the ring.
+	Sign(rand io.Reader, message []byte, ringKeys [][]byte, sk []byte) (RingSignature, error)
+	// Decode decodes a signature from its friendly string representation.
+	Decode(data string) (RingSignature, error)
+}
+
+// RingSignature is a ring signature produced by a Scheme.
+type RingSignature interface {
+	// Verify verifies the validity of the message signature.
+	Verify(message []byte) bool
+	// Members returns the public keys of the ring.
+	Members() [][]byte
+	// Encode encodes the signature to a friendly string representation.
+	Encode() (string, error)
+}
+
+var (
+	// EC is the elliptic curve (P-384) ring signature scheme.
+	EC Scheme = ecScheme{}
+
+	// Lattice is the experimental post-quantum ring signature scheme.
+	Lattice Scheme = latticeScheme{}
+)
+
+var (
+	_ RingSignature = (*Signature)(nil)
+	_ RingSignature = (*LatticeSignature)(nil)
+)
+
+// LookupScheme returns the scheme with the given name.
+func LookupScheme(name string) (Scheme, error) {
+	for _, s := range []Scheme{EC, Lattice} {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+
+	return nil, ErrUnknownScheme
+}
+
+// signerIndex finds the position of a public key in the ring.
+func signerIndex(pk []byte, ringKeys [][]byte) (int, error) {
+	for i, k := range ringKeys {
+		if bytes.Equal(k, pk) {
+			return i, nil
+		}
+	}
+
+	return 0, ErrSignerNotInRing
+}
+
+type ecScheme struct{}
+
+func (ecScheme) Name() string {
+	return "ec"
+}
+
+func (ecScheme) Generate(rand io.Reader) ([]byte, []byte) {
+	return Generate(rand)
+}
+
+func (ecScheme) Public(sk []byte) []byte {
+	return PrivateKey(sk).Public()
+}
+
+func (ecScheme) Sign(rand io.Reader, message []byte, ringKeys [][]byte, sk []byte) (RingSignature, error) {
+	i, err := signerIndex(PrivateKey(sk).Public(), ringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]PublicKey, len(ringKeys))
+	for j, k := range ringKeys {
+		keys[j] = k
+	}
+
+	sig, err := PrivateKey(sk).Sign(rand, message, keys, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+func (ecScheme) Decode(data string) (RingSignature, error) {
+	sig := &Signature{}
+	if err := sig.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+type latticeScheme struct{}
+
+func (latticeScheme) Name() string {
+	return "lattice"
+}
+
+func (latticeScheme) Generate(rand io.Reader) ([]byte, []byte) {
+	return GenerateLattice(rand)
+}
+
+func (latticeScheme) Public(sk []byte) []byte {
+	return LatticePrivateKey(sk).Public()
+}
+
+func (latticeScheme) Sign(rand io.Reader, message []byte, ringKeys [][]byte, sk []byte) (RingSignature, error) {
+	if len(sk) != latticeSeedSize {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	i, err := signerIndex(LatticePrivateKey(sk).Public(), ringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]LatticePublicKey, len(ringKeys))
+	for j, k := range ringKeys {
+		keys[j] = k
+	}
+
+	sig, err := LatticePrivateKey(sk).Sign(rand, message, keys, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+func (latticeScheme) Decode(data string) (RingSignature, error) {
+	sig := &LatticeSignature{}
+	if err := sig.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
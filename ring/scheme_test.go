@@ -0,0 +1,45 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheme(t *testing.T) {
+	message := []byte("same interface, different keys")
+
+	for _, scheme := range []Scheme{EC, Lattice} {
+		t.Run(scheme.Name(), func(t *testing.T) {
+			found, err := LookupScheme(scheme.Name())
+			assert.NoError(t, err, "LookupScheme()")
+			assert.Equal(t, scheme, found)
+
+			alicePub, alicePriv := scheme.Generate(nil)
+			bobPub, _ := scheme.Generate(nil)
+			_, evePriv := scheme.Generate(nil)
+			ringKeys := [][]byte{alicePub, bobPub}
+			assert.Equal(t, alicePub, scheme.Public(alicePriv))
+
+			sig, err := scheme.Sign(nil, message, ringKeys, alicePriv)
+			assert.NoError(t, err, "Sign()")
+			assert.True(t, sig.Verify(message))
+			assert.False(t, sig.Verify([]byte("another message")))
+			assert.Equal(t, ringKeys, sig.Members())
+
+			_, err = scheme.Sign(nil, message, ringKeys, evePriv)
+			assert.Equal(t, ErrSignerNotInRing, err)
+
+			encoded, err := sig.Encode()
+			assert.NoError(t, err, "Encode()")
+			decoded, err := scheme.Decode(encoded)
+			assert.NoError(t, err, "Decode()")
+			assert.True(t, decoded.Verify(message))
+		})
+	}
+
+	t.Run("Rejects unknown schemes", func(t *testing.T) {
+		_, err := LookupScheme("rot13")
+		assert.Equal(t, ErrUnknownScheme, err)
+	})
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/t-bast/ring-signatures/store"
+	"github.com/urfave/cli"
+)
+
+var ringCommand = cli.Command{
+	Name:  "ring",
+	Usage: "manage named rings of public keys",
+	Subcommands: []cli.Command{
+		{
+			Name:      "create",
+			Usage:     "create a named ring",
+			UsageText: "ring-signatures ring create --ring c4r0l --ring b0b team",
+			ArgsUsage: "<name>",
+			Action:    ringCreate,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "ring, r",
+					Usage: "public keys of the ring members",
+				},
+				cli.StringFlag{
+					Name:  "ring-jwks",
+					Usage: "JSON Web Key Set file containing the public keys of the ring members",
+				},
+			},
+		},
+		{
+			Name:  "add",
+			Usage: "add public keys to a named ring",
+			UsageText: "ring-signatures ring add team 4l1c3\n" +
+				"   Members of other saved rings can also be referred to by a prefix of their fingerprint:\n" +
+				"   ring-signatures ring add team 3f2a9c",
+			ArgsUsage: "<name> <public-key or fingerprint>...",
+			Action:    ringAdd,
+		},
+		{
+			Name:  "remove",
+			Usage: "remove public keys from a named ring",
+			UsageText: "ring-signatures ring remove team b0b\n" +
+				"   Members can also be referred to by a prefix of their fingerprint:\n" +
+				"   ring-signatures ring remove team 3f2a9c",
+			ArgsUsage: "<name> <public-key or fingerprint>...",
+			Action:    ringRemove,
+		},
+		{
+			Name:      "list",
+			Usage:     "list named rings",
+			UsageText: "ring-signatures ring list",
+			Action:    ringList,
+		},
+		{
+			Name:      "show",
+			Usage:     "show the public keys of a named ring",
+			UsageText: "ring-signatures ring show team",
+			ArgsUsage: "<name>",
+			Action:    ringShow,
+		},
+		{
+			Name:  "validity",
+			Usage: "set the validity period of a ring member",
+			UsageText: "ring-signatures ring validity --not-before 2018-01-01T00:00:00Z" +
+				" --not-after 2019-01-01T00:00:00Z team 3f2a9c",
+			ArgsUsage: "<name> <public-key or fingerprint>",
+			Action:    ringValidity,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "not-before",
+					Usage: "RFC 3339 time from which the key is valid (unbounded if empty)",
+				},
+				cli.StringFlag{
+					Name:  "not-after",
+					Usage: "RFC 3339 time after which the key is expired (unbounded if empty)",
+				},
+			},
+		},
+		{
+			Name:      "export",
+			Usage:     "export a named ring as a JSON Web Key Set",
+			UsageText: "ring-signatures ring export team > team.jwks",
+			ArgsUsage: "<name>",
+			Action:    ringExport,
+		},
+	},
+}
+
+// openStore opens the store in the configured directory.
+func openStore(c *cli.Context) (*store.Store, error) {
+	dir := c.GlobalString("config-dir")
+	if len(dir) == 0 {
+		var err error
+		dir, err = store.DefaultDir()
+		if err != nil {
+			return nil, cli.NewExitError(err, 1)
+		}
+	}
+
+	return store.New(dir), nil
+}
+
+// loadNamedRing loads the ring with the name given as first argument.
+func loadNamedRing(c *cli.Context) (*store.Store, *store.Ring, error) {
+	name := c.Args().First()
+	if len(name) == 0 {
+		return nil, nil, cli.NewExitError("you need to specify the name of the ring", 1)
+	}
+
+	s, err := openStore(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := s.Load(name)
+	if err != nil {
+		return nil, nil, cli.NewExitError(err, 1)
+	}
+
+	return s, r, nil
+}
+
+// storedDescriptors returns the members of a saved ring with their
+// validity period.
+func storedDescriptors(c *cli.Context, name string) ([]ring.KeyDescriptor, error) {
+	s, err := openStore(c)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.Load(name)
+	if err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	return r.Descriptors(), nil
+}
+
+// knownDescriptors returns the members of all saved rings with their
+// validity period, so that keys given directly can be checked too.
+// It is best effort: a missing store or a ring that can't be loaded
+// doesn't prevent signing.
+func knownDescriptors(c *cli.Context) []ring.KeyDescriptor {
+	s, err := openStore(c)
+	if err != nil {
+		return nil
+	}
+
+	names, err := s.List()
+	if err != nil {
+		return nil
+	}
+
+	var descriptors []ring.KeyDescriptor
+	for _, name := range names {
+		r, err := s.Load(name)
+		if err != nil {
+			continue
+		}
+
+		descriptors = append(descriptors, r.Descriptors()...)
+	}
+
+	return descriptors
+}
+
+// decodePublicKeys decodes public keys from their friendly string format.
+// Members of saved rings can also be referred to by a fingerprint prefix.
+func decodePublicKeys(c *cli.Context, keys []string) ([]ring.PublicKey, error) {
+	var pubKeys []ring.PublicKey
+	for _, key := range keys {
+		pkBytes, err := ring.ConfigDecodeKey(key)
+		if err == nil {
+			if _, err := ring.PublicKey(pkBytes).Fingerprint(); err == nil {
+				pubKeys = append(pubKeys, ring.PublicKey(pkBytes))
+				continue
+			}
+		}
+
+		s, err := openStore(c)
+		if err != nil {
+			return nil, err
+		}
+
+		pk, err := s.Lookup(key)
+		if err == store.ErrKeyNotFound {
+			return nil, cli.NewExitError(fmt.Sprintf("invalid public key: %s", key), 1)
+		} else if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("%s: %s", key, err), 1)
+		}
+
+		pubKeys = append(pubKeys, pk)
+	}
+
+	return pubKeys, nil
+}
+
+func ringCreate(c *cli.Context) error {
+	name := c.Args().First()
+	if len(name) == 0 {
+		return cli.NewExitError("you need to specify the name of the ring", 1)
+	}
+
+	var keys []ring.PublicKey
+	if len(c.String("ring-jwks")) > 0 || len(c.StringSlice("ring")) > 0 {
+		var err error
+		keys, err = ringFromFlags(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	s, err := openStore(c)
+	if err != nil {
+		return err
+	}
+
+	r, err := s.Create(name, keys)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Created ring %s with %d members.\n", r.Name, len(r.Keys))
+
+	return nil
+}
+
+func ringAdd(c *cli.Context) error {
+	s, r, err := loadNamedRing(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := decodePublicKeys(c, c.Args().Tail())
+	if err != nil {
+		return err
+	}
+
+	err = r.Add(keys...)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	err = s.Save(r)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Ring %s now has %d members.\n", r.Name, len(r.Keys))
+
+	return nil
+}
+
+func ringRemove(c *cli.Context) error {
+	s, r, err := loadNamedRing(c)
+	if err != nil {
+		return err
+	}
+
+	var keys []ring.PublicKey
+	for _, arg := range c.Args().Tail() {
+		pk, err := r.Lookup(arg)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("%s: %s", arg, err), 1)
+		}
+
+		keys = append(keys, pk)
+	}
+
+	err = r.Remove(keys...)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	err = s.Save(r)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Ring %s now has %d members.\n", r.Name, len(r.Keys))
+
+	return nil
+}
+
+func ringList(c *cli.Context) error {
+	s, err := openStore(c)
+	if err != nil {
+		return err
+	}
+
+	names, err := s.List()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	for _, name := range names {
+		r, err := s.Load(name)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		fmt.Printf("%s (%d members)\n", r.Name, len(r.Keys))
+		for _, k := range r.Keys {
+			fmt.Printf("   %s\n", fingerprint(k))
+		}
+	}
+
+	return nil
+}
+
+func ringShow(c *cli.Context) error {
+	_, r, err := loadNamedRing(c)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range r.Descriptors() {
+		fmt.Printf("%d: %s\n", i, fingerprint(d.PublicKey))
+		fmt.Printf("   %s\n", ring.ConfigEncodeKey(d.PublicKey))
+		if !d.NotBefore.IsZero() {
+			fmt.Printf("   Not before: %s\n", d.NotBefore.Format(time.RFC3339))
+		}
+
+		if !d.NotAfter.IsZero() {
+			fmt.Printf("   Not after: %s\n", d.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func ringValidity(c *cli.Context) error {
+	s, r, err := loadNamedRing(c)
+	if err != nil {
+		return err
+	}
+
+	if len(c.Args().Tail()) != 1 {
+		return cli.NewExitError("you need to specify the ring member", 1)
+	}
+
+	pk, err := r.Lookup(c.Args().Tail()[0])
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var bounds [2]time.Time
+	for i, name := range []string{"not-before", "not-after"} {
+		if v := c.String(name); len(v) > 0 {
+			bounds[i], err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return cli.NewExitError(fmt.Sprintf("invalid %s time: use the RFC 3339 format", name), 1)
+			}
+		}
+	}
+
+	err = r.SetValidity(pk, bounds[0], bounds[1])
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	err = s.Save(r)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Updated the validity of %s in ring %s.\n", fingerprint(pk), r.Name)
+
+	return nil
+}
+
+func ringExport(c *cli.Context) error {
+	_, r, err := loadNamedRing(c)
+	if err != nil {
+		return err
+	}
+
+	b, err := ring.RingToJWKS(r.Keys)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
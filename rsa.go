@@ -0,0 +1,194 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/urfave/cli"
+)
+
+var rsaCommand = cli.Command{
+	Name:  "rsa",
+	Usage: "sign with a ring of RSA keys",
+	Subcommands: []cli.Command{
+		{
+			Name:  "sign",
+			Usage: "sign a message with a ring of RSA keys",
+			UsageText: "ring-signatures rsa sign --message \"hello!\" --private-key-file alice.pem" +
+				" --ring-file alice.pub.pem --ring-file bob.pub.pem",
+			Action: rsaSign,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message to sign",
+				},
+				cli.StringFlag{
+					Name:  "private-key-file, k",
+					Usage: "PEM file containing your RSA private key",
+				},
+				cli.StringSliceFlag{
+					Name:  "ring-file, r",
+					Usage: "PEM file containing an RSA public key of the ring",
+				},
+			},
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify a message signature",
+			UsageText: "ring-signatures rsa verify --message \"hello!\" --signature s1GN4tUr3",
+			Action:    rsaVerify,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "signed message",
+				},
+				cli.StringFlag{
+					Name:  "signature, s",
+					Usage: "signature to verify",
+				},
+			},
+		},
+	},
+}
+
+// readPEM reads the first PEM block of a file.
+func readPEM(file string) (*pem.Block, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, cli.NewExitError(fmt.Sprintf("%s is not a PEM file", file), 1)
+	}
+
+	return block, nil
+}
+
+// readRSAPublicKey reads a PKCS #1 or PKIX RSA public key from a PEM file.
+func readRSAPublicKey(file string) (*rsa.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if pk, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return pk, nil
+	}
+
+	pk, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, cli.NewExitError(fmt.Sprintf("invalid public key in %s", file), 1)
+	}
+
+	rsaKey, ok := pk.(*rsa.PublicKey)
+	if !ok {
+		return nil, cli.NewExitError(fmt.Sprintf("%s is not an RSA public key", file), 1)
+	}
+
+	return rsaKey, nil
+}
+
+// readRSAPrivateKey reads a PKCS #1 or PKCS #8 RSA private key from a PEM file.
+func readRSAPrivateKey(file string) (*rsa.PrivateKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if sk, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return sk, nil
+	}
+
+	sk, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, cli.NewExitError(fmt.Sprintf("invalid private key in %s", file), 1)
+	}
+
+	rsaKey, ok := sk.(*rsa.PrivateKey)
+	if !ok {
+		return nil, cli.NewExitError(fmt.Sprintf("%s is not an RSA private key", file), 1)
+	}
+
+	return rsaKey, nil
+}
+
+func rsaSign(c *cli.Context) error {
+	m := c.String("message")
+	if len(m) == 0 {
+		return cli.NewExitError("you need to specify a message to sign", 1)
+	}
+
+	if len(c.String("private-key-file")) == 0 {
+		return cli.NewExitError("you need to specify the private key to use for signing", 1)
+	}
+
+	sk, err := readRSAPrivateKey(c.String("private-key-file"))
+	if err != nil {
+		return err
+	}
+
+	signerIndex := -1
+	var ringKeys []*rsa.PublicKey
+	for _, file := range c.StringSlice("ring-file") {
+		pk, err := readRSAPublicKey(file)
+		if err != nil {
+			return err
+		}
+
+		if pk.E == sk.E && pk.N.Cmp(sk.N) == 0 {
+			signerIndex = len(ringKeys)
+		}
+
+		ringKeys = append(ringKeys, pk)
+	}
+
+	if signerIndex < 0 {
+		return cli.NewExitError(ring.ErrSignerNotInRing, 1)
+	}
+
+	sig, err := ring.SignRSA(crand.Reader, []byte(m), ringKeys, signerIndex, sk)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sigStr, err := sig.Encode()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(sigStr)
+
+	return nil
+}
+
+func rsaVerify(c *cli.Context) error {
+	sigStr := c.String("signature")
+	if len(sigStr) == 0 {
+		return cli.NewExitError("you need to specify the signature to verify", 1)
+	}
+
+	sig := &ring.RSASignature{}
+	err := sig.Decode(sigStr)
+	if err != nil {
+		return cli.NewExitError("invalid signature", 1)
+	}
+
+	if !sig.Verify([]byte(c.String("message"))) {
+		return cli.NewExitError(ring.ErrInvalidSignature, 1)
+	}
+
+	fmt.Println("Signature is valid.")
+	fmt.Printf("Ring members (%d):\n", len(sig.Ring()))
+	for i, pk := range sig.Ring() {
+		fmt.Printf("%d: %d-bit RSA key %s\n", i, pk.N.BitLen(), keyPrefix(pk.N.Bytes()))
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/t-bast/ring-signatures/server"
+	"github.com/urfave/cli"
+)
+
+var serveCommand = cli.Command{
+	Name:  "serve",
+	Usage: "start an HTTP API to verify (and optionally sign) messages",
+	UsageText: "ring-signatures serve --listen 127.0.0.1:8080\n" +
+		"   Endpoints: POST /verify, POST /verify/batch, POST /sign, GET /rings, GET /rings/<name>.\n" +
+		"   Signing is only enabled when signing keys are provided, and requires a sign token:\n" +
+		"   clients must send it in an \"Authorization: Bearer <token>\" header.",
+	Action: serve,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "listen, l",
+			Usage: "address to listen on",
+			Value: "127.0.0.1:8080",
+		},
+		cli.StringSliceFlag{
+			Name:  "signing-key-file",
+			Usage: "file containing a private key the server can sign with",
+		},
+		cli.StringFlag{
+			Name:  "sign-token-file",
+			Usage: "file containing the token signing requests must present (required with signing keys)",
+		},
+		cli.Int64Flag{
+			Name:  "max-request-size",
+			Usage: "maximum size of a request body in bytes",
+			Value: server.DefaultMaxRequestSize,
+		},
+		cli.IntFlag{
+			Name:  "max-batch-size",
+			Usage: "maximum number of signatures in a batch verification",
+			Value: server.DefaultMaxBatchSize,
+		},
+	},
+}
+
+func serve(c *cli.Context) error {
+	var signingKeys []ring.PrivateKey
+	for _, keyFile := range c.StringSlice("signing-key-file") {
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		sk, err := ring.ConfigDecodeKey(strings.TrimSpace(string(b)))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("invalid private key in %s", keyFile), 1)
+		}
+
+		signingKeys = append(signingKeys, ring.PrivateKey(sk))
+	}
+
+	var signToken string
+	if tokenFile := c.String("sign-token-file"); len(tokenFile) > 0 {
+		b, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		signToken = strings.TrimSpace(string(b))
+	}
+
+	st, err := openStore(c)
+	if err != nil {
+		return err
+	}
+
+	s, err := server.New(server.Config{
+		Store:          st,
+		SigningKeys:    signingKeys,
+		SignToken:      signToken,
+		MaxRequestSize: c.Int64("max-request-size"),
+		MaxBatchSize:   c.Int("max-batch-size"),
+	})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	addr := c.String("listen")
+	fmt.Printf("Listening on %s...\n", addr)
+
+	// Timeouts keep slow clients from holding connections open forever.
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	return cli.NewExitError(srv.ListenAndServe(), 1)
+}
@@ -0,0 +1,505 @@
+// Package server exposes ring signatures through a JSON HTTP API.
+package server
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/t-bast/ring-signatures/store"
+)
+
+const (
+	// DefaultMaxRequestSize is the default maximum size of a request body.
+	DefaultMaxRequestSize = 1 << 20
+
+	// DefaultMaxBatchSize is the default maximum number of signatures
+	// in a batch verification request.
+	DefaultMaxBatchSize = 100
+)
+
+// ErrMissingSignToken is returned when signing keys are configured without
+// a token to authenticate signing requests.
+var ErrMissingSignToken = errors.New("a sign token is required to enable signing")
+
+// Error codes returned by the API.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeRequestTooLarge  = "request_too_large"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeNotFound         = "not_found"
+	CodeInvalidSignature = "invalid_signature"
+	CodeUntrustedRing    = "untrusted_ring"
+	CodePolicyViolation  = "policy_violation"
+	CodeSigningDisabled  = "signing_disabled"
+	CodeUnauthorized     = "unauthorized"
+	CodeUnknownKey       = "unknown_key"
+	CodeInternal         = "internal_error"
+)
+
+// Config configures the server.
+type Config struct {
+	// Store gives access to named rings. It is optional.
+	Store *store.Store
+	// SigningKeys are the keys the server can sign with.
+	// Signing is disabled when there are none.
+	SigningKeys []ring.PrivateKey
+	// SignToken authenticates signing requests, which must send it in an
+	// "Authorization: Bearer <token>" header. It is required when there are
+	// signing keys: otherwise anyone who can reach the server could sign
+	// with its keys.
+	SignToken string
+	// MaxRequestSize is the maximum size of a request body.
+	MaxRequestSize int64
+	// MaxBatchSize is the maximum number of signatures in a batch.
+	MaxBatchSize int
+}
+
+// Server handles HTTP requests.
+type Server struct {
+	store          *store.Store
+	signingKeys    map[ring.Fingerprint]ring.PrivateKey
+	signToken      string
+	maxRequestSize int64
+	maxBatchSize   int
+	mux            *http.ServeMux
+}
+
+// New creates a server.
+func New(config Config) (*Server, error) {
+	s := &Server{
+		store:          config.Store,
+		signingKeys:    make(map[ring.Fingerprint]ring.PrivateKey),
+		signToken:      config.SignToken,
+		maxRequestSize: config.MaxRequestSize,
+		maxBatchSize:   config.MaxBatchSize,
+		mux:            http.NewServeMux(),
+	}
+
+	if s.maxRequestSize <= 0 {
+		s.maxRequestSize = DefaultMaxRequestSize
+	}
+
+	if s.maxBatchSize <= 0 {
+		s.maxBatchSize = DefaultMaxBatchSize
+	}
+
+	if len(config.SigningKeys) > 0 && len(config.SignToken) == 0 {
+		return nil, ErrMissingSignToken
+	}
+
+	for _, sk := range config.SigningKeys {
+		f, err := sk.Public().Fingerprint()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid signing key")
+		}
+
+		s.signingKeys[f] = sk
+	}
+
+	s.mux.HandleFunc("/verify", s.handleVerify)
+	s.mux.HandleFunc("/verify/batch", s.handleBatchVerify)
+	s.mux.HandleFunc("/sign", s.handleSign)
+	s.mux.HandleFunc("/rings", s.handleListRings)
+	s.mux.HandleFunc("/rings/", s.handleGetRing)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Error is a structured API error.
+type Error struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(status int, code string, message string) *Error {
+	return &Error{status: status, Code: code, Message: message}
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a structured error response.
+func writeError(w http.ResponseWriter, err *Error) {
+	writeJSON(w, err.status, struct {
+		Error *Error `json:"error"`
+	}{err})
+}
+
+// readJSON decodes the request body, enforcing the size limit.
+func (s *Server) readJSON(w http.ResponseWriter, r *http.Request, v interface{}) *Error {
+	if r.Method != http.MethodPost {
+		return newError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "use POST")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxRequestSize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			return newError(http.StatusRequestEntityTooLarge, CodeRequestTooLarge, "the request is too large")
+		}
+
+		return newError(http.StatusBadRequest, CodeBadRequest, err.Error())
+	}
+
+	return nil
+}
+
+// PolicyRequest is the signed attributes policy of a verification request.
+type PolicyRequest struct {
+	Context string            `json:"context,omitempty"`
+	Claims  map[string]string `json:"claims,omitempty"`
+	MaxAge  string            `json:"max_age,omitempty"`
+}
+
+// VerifyRequest is a signature verification request.
+type VerifyRequest struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+	// Ring, if set, lists the public keys the signature's ring must match.
+	Ring []string `json:"ring,omitempty"`
+	// RingName, if set, is a named ring the signature's ring must match.
+	RingName string `json:"ring_name,omitempty"`
+	// Policy, if set, is checked against the signed attributes.
+	Policy *PolicyRequest `json:"policy,omitempty"`
+}
+
+// VerifyResponse is the result of a signature verification.
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error *Error `json:"error,omitempty"`
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if err := s.readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.verify(&req); err != nil {
+		if err.status == http.StatusOK {
+			writeJSON(w, http.StatusOK, VerifyResponse{Valid: false, Error: err})
+		} else {
+			writeError(w, err)
+		}
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, VerifyResponse{Valid: true})
+}
+
+// BatchVerifyRequest verifies several signatures at once.
+type BatchVerifyRequest struct {
+	Items []*VerifyRequest `json:"items"`
+}
+
+// BatchVerifyResponse contains the results of a batch verification,
+// in the same order as the request items.
+type BatchVerifyResponse struct {
+	Results []VerifyResponse `json:"results"`
+}
+
+func (s *Server) handleBatchVerify(w http.ResponseWriter, r *http.Request) {
+	var req BatchVerifyRequest
+	if err := s.readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if len(req.Items) > s.maxBatchSize {
+		writeError(w, newError(http.StatusRequestEntityTooLarge, CodeRequestTooLarge, "too many items in the batch"))
+		return
+	}
+
+	res := BatchVerifyResponse{Results: make([]VerifyResponse, len(req.Items))}
+	for i, item := range req.Items {
+		if item == nil {
+			res.Results[i].Error = newError(http.StatusBadRequest, CodeBadRequest, "missing item")
+			continue
+		}
+
+		if err := s.verify(item); err != nil {
+			res.Results[i].Error = err
+			continue
+		}
+
+		res.Results[i].Valid = true
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// verify verifies a signature.
+// Errors with an OK status are verification failures, the others are
+// invalid requests.
+func (s *Server) verify(req *VerifyRequest) *Error {
+	if len(req.Message) == 0 || len(req.Signature) == 0 {
+		return newError(http.StatusBadRequest, CodeBadRequest, "message and signature are required")
+	}
+
+	// Clients that deduplicate signatures by their encoding rely on it
+	// being canonical.
+	opts := ring.DefaultDecodeOptions
+	opts.StrictEncoding = true
+
+	sig := &ring.Signature{}
+	if err := sig.DecodeWithOptions(req.Signature, opts); err != nil {
+		return newError(http.StatusOK, CodeInvalidSignature, "the signature could not be decoded")
+	}
+
+	policy := ring.Policy{}
+	if req.Policy != nil {
+		policy.Context = req.Policy.Context
+		policy.Claims = req.Policy.Claims
+		if len(req.Policy.MaxAge) > 0 {
+			maxAge, err := time.ParseDuration(req.Policy.MaxAge)
+			if err != nil {
+				return newError(http.StatusBadRequest, CodeBadRequest, "invalid max_age")
+			}
+
+			policy.MaxAge = maxAge
+		}
+	}
+
+	expected, apiErr := s.requestRing(req.Ring, req.RingName)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if !sig.Verify([]byte(req.Message)) {
+		return newError(http.StatusOK, CodeInvalidSignature, ring.ErrInvalidSignature.Error())
+	}
+
+	if expected != nil && !ring.NewKeySet(sig.Ring()...).Equal(ring.NewKeySet(expected...)) {
+		return newError(http.StatusOK, CodeUntrustedRing, "the signature was not produced with the expected ring")
+	}
+
+	if err := policy.Check(sig.Attributes()); err != nil {
+		return newError(http.StatusOK, CodePolicyViolation, err.Error())
+	}
+
+	return nil
+}
+
+// requestRing returns the ring given explicitly or by name, if any.
+func (s *Server) requestRing(keys []string, name string) ([]ring.PublicKey, *Error) {
+	if len(name) > 0 {
+		if s.store == nil {
+			return nil, newError(http.StatusNotFound, CodeNotFound, "named rings are not available")
+		}
+
+		r, err := s.store.Load(name)
+		if err == store.ErrRingNotFound || err == store.ErrInvalidName {
+			return nil, newError(http.StatusNotFound, CodeNotFound, err.Error())
+		} else if err != nil {
+			return nil, newError(http.StatusInternalServerError, CodeInternal, "could not load ring")
+		}
+
+		return r.Keys, nil
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	ringKeys := make([]ring.PublicKey, len(keys))
+	for i, k := range keys {
+		pk, err := ring.ConfigDecodeKey(k)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, CodeBadRequest, "invalid public key in ring")
+		}
+
+		ringKeys[i] = pk
+	}
+
+	return ringKeys, nil
+}
+
+// authorizeSign checks the bearer token of a signing request.
+func (s *Server) authorizeSign(r *http.Request) bool {
+	const scheme = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(s.signToken) == 0 || !strings.HasPrefix(auth, scheme) {
+		return false
+	}
+
+	token := auth[len(scheme):]
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.signToken)) == 1
+}
+
+// SignRequest asks the server to sign a message with one of its keys.
+type SignRequest struct {
+	Message string `json:"message"`
+	// Key is the fingerprint (or a prefix of it) of the signing key.
+	Key      string   `json:"key"`
+	Ring     []string `json:"ring,omitempty"`
+	RingName string   `json:"ring_name,omitempty"`
+	// Canonical sorts the ring to hide the position of the signer.
+	Canonical bool `json:"canonical,omitempty"`
+}
+
+// SignResponse contains the produced signature.
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	if len(s.signingKeys) == 0 {
+		writeError(w, newError(http.StatusForbidden, CodeSigningDisabled, "the server has no signing keys"))
+		return
+	}
+
+	if !s.authorizeSign(r) {
+		writeError(w, newError(http.StatusUnauthorized, CodeUnauthorized, "a valid sign token is required"))
+		return
+	}
+
+	var req SignRequest
+	if err := s.readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if len(req.Message) == 0 {
+		writeError(w, newError(http.StatusBadRequest, CodeBadRequest, "message is required"))
+		return
+	}
+
+	sk, apiErr := s.signingKey(req.Key)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	ringKeys, apiErr := s.requestRing(req.Ring, req.RingName)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	if req.Canonical {
+		ringKeys = ring.CanonicalRing(ringKeys)
+	}
+
+	sig, err := sk.SignAuto(crand.Reader, []byte(req.Message), ringKeys)
+	if err != nil {
+		writeError(w, newError(http.StatusBadRequest, CodeBadRequest, err.Error()))
+		return
+	}
+
+	encoded, err := sig.Encode()
+	if err != nil {
+		writeError(w, newError(http.StatusInternalServerError, CodeInternal, "could not encode signature"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SignResponse{Signature: encoded})
+}
+
+// signingKey finds the signing key matching a fingerprint prefix.
+func (s *Server) signingKey(prefix string) (ring.PrivateKey, *Error) {
+	var found ring.PrivateKey
+	for f, sk := range s.signingKeys {
+		if !f.HasPrefix(prefix) {
+			continue
+		}
+
+		if found != nil {
+			return nil, newError(http.StatusBadRequest, CodeUnknownKey, "several signing keys match that fingerprint")
+		}
+
+		found = sk
+	}
+
+	if found == nil {
+		return nil, newError(http.StatusNotFound, CodeUnknownKey, "unknown signing key")
+	}
+
+	return found, nil
+}
+
+// RingResponse describes a named ring.
+type RingResponse struct {
+	Name         string   `json:"name"`
+	Keys         []string `json:"keys"`
+	Fingerprints []string `json:"fingerprints"`
+}
+
+func (s *Server) handleListRings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, newError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "use GET"))
+		return
+	}
+
+	names := []string{}
+	if s.store != nil {
+		stored, err := s.store.List()
+		if err != nil {
+			writeError(w, newError(http.StatusInternalServerError, CodeInternal, "could not list rings"))
+			return
+		}
+
+		names = append(names, stored...)
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Rings []string `json:"rings"`
+	}{names})
+}
+
+func (s *Server) handleGetRing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, newError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "use GET"))
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/rings/")
+	ringKeys, apiErr := s.requestRing(nil, name)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	if ringKeys == nil && len(name) == 0 {
+		writeError(w, newError(http.StatusNotFound, CodeNotFound, "ring not found"))
+		return
+	}
+
+	res := RingResponse{
+		Name:         name,
+		Keys:         make([]string, len(ringKeys)),
+		Fingerprints: make([]string, len(ringKeys)),
+	}
+
+	for i, pk := range ringKeys {
+		res.Keys[i] = ring.ConfigEncodeKey(pk)
+		if f, err := pk.Fingerprint(); err == nil {
+			res.Fingerprints[i] = f.String()
+		}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/t-bast/ring-signatures/store"
+)
+
+func post(t *testing.T, url string, body interface{}, res interface{}) int {
+	return postWithAuth(t, url, "", body, res)
+}
+
+func postWithToken(t *testing.T, url string, token string, body interface{}, res interface{}) int {
+	return postWithAuth(t, url, "Bearer "+token, body, res)
+}
+
+func postWithAuth(t *testing.T, url string, auth string, body interface{}, res interface{}) int {
+	b, err := json.Marshal(body)
+	assert.NoError(t, err, "json.Marshal()")
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	assert.NoError(t, err, "http.NewRequest()")
+	req.Header.Set("Content-Type", "application/json")
+	if len(auth) > 0 {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err, "http.Do()")
+	defer resp.Body.Close()
+
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(res), "Decode()")
+	return resp.StatusCode
+}
+
+type errorResponse struct {
+	Error *Error `json:"error"`
+}
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring-server")
+	assert.NoError(t, err, "ioutil.TempDir()")
+	defer os.RemoveAll(dir)
+
+	alicePub, alicePriv := ring.Generate(nil)
+	bobPub, _ := ring.Generate(nil)
+	evePub, evePriv := ring.Generate(nil)
+
+	st := store.New(dir)
+	_, err = st.Create("team", []ring.PublicKey{alicePub, bobPub})
+	assert.NoError(t, err, "Create()")
+
+	token := "s3cr3t"
+	s, err := New(Config{Store: st, SigningKeys: []ring.PrivateKey{alicePriv}, SignToken: token, MaxRequestSize: 16 << 10, MaxBatchSize: 3})
+	assert.NoError(t, err, "New()")
+
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	message := "hello from the ring"
+	sig, err := alicePriv.Sign(nil, []byte(message), []ring.PublicKey{alicePub, bobPub}, 0)
+	assert.NoError(t, err, "Sign()")
+	encoded, err := sig.Encode()
+	assert.NoError(t, err, "Encode()")
+
+	forged, err := evePriv.Sign(nil, []byte(message), []ring.PublicKey{evePub, bobPub}, 0)
+	assert.NoError(t, err, "Sign()")
+	forgedEncoded, err := forged.Encode()
+	assert.NoError(t, err, "Encode()")
+
+	t.Run("Verifies signature", func(t *testing.T) {
+		var res VerifyResponse
+		status := post(t, ts.URL+"/verify", VerifyRequest{Message: message, Signature: encoded, RingName: "team"}, &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.True(t, res.Valid)
+		assert.Nil(t, res.Error)
+	})
+
+	t.Run("Rejects invalid signature", func(t *testing.T) {
+		var res VerifyResponse
+		status := post(t, ts.URL+"/verify", VerifyRequest{Message: "other", Signature: encoded}, &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, res.Valid)
+		assert.Equal(t, CodeInvalidSignature, res.Error.Code)
+	})
+
+	t.Run("Rejects untrusted ring", func(t *testing.T) {
+		var res VerifyResponse
+		status := post(t, ts.URL+"/verify", VerifyRequest{
+			Message:   message,
+			Signature: forgedEncoded,
+			Ring:      []string{ring.ConfigEncodeKey(alicePub), ring.ConfigEncodeKey(bobPub)},
+		}, &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, res.Valid)
+		assert.Equal(t, CodeUntrustedRing, res.Error.Code)
+	})
+
+	t.Run("Checks policy", func(t *testing.T) {
+		var res VerifyResponse
+		status := post(t, ts.URL+"/verify", VerifyRequest{Message: message, Signature: encoded, Policy: &PolicyRequest{Context: "votes"}}, &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, res.Valid)
+		assert.Equal(t, CodePolicyViolation, res.Error.Code)
+	})
+
+	t.Run("Rejects bad requests", func(t *testing.T) {
+		var res errorResponse
+		status := post(t, ts.URL+"/verify", map[string]string{"msg": message}, &res)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, CodeBadRequest, res.Error.Code)
+
+		status = post(t, ts.URL+"/verify", VerifyRequest{Message: message, Signature: encoded, RingName: "nope"}, &res)
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, CodeNotFound, res.Error.Code)
+
+		resp, err := http.Get(ts.URL + "/verify")
+		assert.NoError(t, err, "http.Get()")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("Rejects large requests", func(t *testing.T) {
+		var res errorResponse
+		status := post(t, ts.URL+"/verify", VerifyRequest{Message: strings.Repeat("a", 32<<10), Signature: encoded}, &res)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+		assert.Equal(t, CodeRequestTooLarge, res.Error.Code)
+	})
+
+	t.Run("Verifies batch", func(t *testing.T) {
+		var res BatchVerifyResponse
+		status := post(t, ts.URL+"/verify/batch", BatchVerifyRequest{Items: []*VerifyRequest{
+			{Message: message, Signature: encoded},
+			{Message: message, Signature: "garbage"},
+			{Message: message, Signature: forgedEncoded, RingName: "team"},
+		}}, &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, res.Results, 3)
+		assert.True(t, res.Results[0].Valid)
+		assert.Equal(t, CodeInvalidSignature, res.Results[1].Error.Code)
+		assert.Equal(t, CodeUntrustedRing, res.Results[2].Error.Code)
+
+		var errRes errorResponse
+		status = post(t, ts.URL+"/verify/batch", BatchVerifyRequest{Items: make([]*VerifyRequest, 4)}, &errRes)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	})
+
+	t.Run("Signs with server keys", func(t *testing.T) {
+		f, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		var res SignResponse
+		status := postWithToken(t, ts.URL+"/sign", token, SignRequest{Message: message, Key: f.ShortID(), RingName: "team", Canonical: true}, &res)
+		assert.Equal(t, http.StatusOK, status)
+
+		sig := &ring.Signature{}
+		assert.NoError(t, sig.Decode(res.Signature), "Decode()")
+		assert.True(t, sig.VerifyCanonical([]byte(message)))
+
+		ef, err := evePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		var errRes errorResponse
+		status = postWithToken(t, ts.URL+"/sign", token, SignRequest{Message: message, Key: ef.ShortID(), RingName: "team"}, &errRes)
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, CodeUnknownKey, errRes.Error.Code)
+	})
+
+	t.Run("Requires a sign token", func(t *testing.T) {
+		_, err := New(Config{SigningKeys: []ring.PrivateKey{alicePriv}})
+		assert.Equal(t, ErrMissingSignToken, err)
+
+		f, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		for _, tok := range []string{"", "wrong", token + "x"} {
+			var errRes errorResponse
+			status := postWithToken(t, ts.URL+"/sign", tok, SignRequest{Message: message, Key: f.ShortID(), RingName: "team"}, &errRes)
+			assert.Equal(t, http.StatusUnauthorized, status)
+			assert.Equal(t, CodeUnauthorized, errRes.Error.Code)
+		}
+
+		for _, auth := range []string{token, "Basic " + token, "bearer " + token} {
+			var errRes errorResponse
+			status := postWithAuth(t, ts.URL+"/sign", auth, SignRequest{Message: message, Key: f.ShortID(), RingName: "team"}, &errRes)
+			assert.Equal(t, http.StatusUnauthorized, status)
+		}
+	})
+
+	t.Run("Disables signing without keys", func(t *testing.T) {
+		s, err := New(Config{})
+		assert.NoError(t, err, "New()")
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sign", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Looks up rings", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/rings")
+		assert.NoError(t, err, "http.Get()")
+		var list struct{ Rings []string }
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		resp.Body.Close()
+		assert.Equal(t, []string{"team"}, list.Rings)
+
+		resp, err = http.Get(ts.URL + "/rings/team")
+		assert.NoError(t, err, "http.Get()")
+		var r RingResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&r))
+		resp.Body.Close()
+		assert.Equal(t, "team", r.Name)
+		assert.Equal(t, []string{ring.ConfigEncodeKey(alicePub), ring.ConfigEncodeKey(bobPub)}, r.Keys)
+		assert.Len(t, r.Fingerprints, 2)
+
+		resp, err = http.Get(ts.URL + "/rings/nope")
+		assert.NoError(t, err, "http.Get()")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
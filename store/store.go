@@ -0,0 +1,456 @@
+// Package store persists named rings of public keys in a local
+// configuration directory.
+package store
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+var (
+	// ErrInvalidName is returned when a ring name contains forbidden characters.
+	ErrInvalidName = errors.New("ring names can only contain letters, digits, '-' and '_'")
+
+	// ErrRingExists is returned when creating a ring that already exists.
+	ErrRingExists = errors.New("a ring with that name already exists")
+
+	// ErrRingNotFound is returned when a ring doesn't exist.
+	ErrRingNotFound = errors.New("ring not found")
+
+	// ErrDuplicateKey is returned when adding a key that is already in the ring.
+	ErrDuplicateKey = errors.New("the key is already in the ring")
+
+	// ErrKeyNotFound is returned when removing a key that isn't in the ring.
+	ErrKeyNotFound = errors.New("the key is not in the ring")
+
+	// ErrAmbiguousKey is returned when a fingerprint prefix matches
+	// several ring members.
+	ErrAmbiguousKey = errors.New("several ring members match that fingerprint: use a longer prefix")
+
+	// ErrInvalidValidity is returned when a validity period ends before
+	// it starts.
+	ErrInvalidValidity = errors.New("the validity period ends before it starts")
+)
+
+const (
+	ringsDir       = "rings"
+	revocationsDir = "revocations"
+)
+
+var validName = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+// DefaultDir returns the default configuration directory.
+// It can be overridden with the RING_SIGNATURES_HOME environment variable.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("RING_SIGNATURES_HOME"); len(dir) > 0 {
+		return dir, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return filepath.Join(dir, "ring-signatures"), nil
+}
+
+// Ring is a named ring of public keys.
+type Ring struct {
+	Name string
+	Keys []ring.PublicKey
+
+	validity map[string]ring.KeyDescriptor
+}
+
+// SetValidity sets the validity period of a ring member.
+// Zero times leave the period unbounded.
+func (r *Ring) SetValidity(pk ring.PublicKey, notBefore, notAfter time.Time) error {
+	if r.Index(pk) < 0 {
+		return ErrKeyNotFound
+	}
+
+	if !notBefore.IsZero() && !notAfter.IsZero() && notAfter.Before(notBefore) {
+		return ErrInvalidValidity
+	}
+
+	if r.validity == nil {
+		r.validity = make(map[string]ring.KeyDescriptor)
+	}
+
+	r.validity[string(pk)] = ring.KeyDescriptor{
+		PublicKey: pk,
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+	}
+
+	return nil
+}
+
+// Descriptors returns the ring members with their validity period.
+func (r *Ring) Descriptors() []ring.KeyDescriptor {
+	descriptors := make([]ring.KeyDescriptor, len(r.Keys))
+	for i, k := range r.Keys {
+		d, ok := r.validity[string(k)]
+		if !ok {
+			d = ring.KeyDescriptor{PublicKey: k}
+		}
+
+		descriptors[i] = d
+	}
+
+	return descriptors
+}
+
+// Index returns the index of the given key in the ring, or -1
+// if the key is not part of the ring.
+func (r *Ring) Index(pk ring.PublicKey) int {
+	for i, k := range r.Keys {
+		if string(k) == string(pk) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Lookup finds the ring member matching the given encoded public key
+// or fingerprint prefix.
+func (r *Ring) Lookup(keyOrPrefix string) (ring.PublicKey, error) {
+	if pk, err := ring.ConfigDecodeKey(keyOrPrefix); err == nil && r.Index(pk) >= 0 {
+		return pk, nil
+	}
+
+	var found ring.PublicKey
+	for _, k := range r.Keys {
+		f, err := k.Fingerprint()
+		if err != nil || !f.HasPrefix(keyOrPrefix) {
+			continue
+		}
+
+		if found != nil {
+			return nil, ErrAmbiguousKey
+		}
+
+		found = k
+	}
+
+	if found == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return found, nil
+}
+
+// Add adds keys to the ring.
+// Keys must be valid P-384 points.
+func (r *Ring) Add(keys ...ring.PublicKey) error {
+	for _, pk := range keys {
+		if _, err := pk.Fingerprint(); err != nil {
+			return err
+		}
+
+		if r.Index(pk) >= 0 {
+			return ErrDuplicateKey
+		}
+
+		r.Keys = append(r.Keys, pk)
+	}
+
+	return nil
+}
+
+// Remove removes keys from the ring.
+func (r *Ring) Remove(keys ...ring.PublicKey) error {
+	for _, pk := range keys {
+		i := r.Index(pk)
+		if i < 0 {
+			return ErrKeyNotFound
+		}
+
+		// Copy the remaining keys so that slices shared with the caller
+		// aren't modified.
+		remaining := make([]ring.PublicKey, 0, len(r.Keys)-1)
+		remaining = append(remaining, r.Keys[:i]...)
+		r.Keys = append(remaining, r.Keys[i+1:]...)
+		delete(r.validity, string(pk))
+	}
+
+	return nil
+}
+
+// ringFile is the on-disk representation of a ring.
+type ringFile struct {
+	Name     string         `json:"name"`
+	Keys     []string       `json:"keys"`
+	Validity []validityFile `json:"validity,omitempty"`
+}
+
+// validityFile is the on-disk representation of a key's validity period.
+// Times use the RFC 3339 format.
+type validityFile struct {
+	Key       string `json:"key"`
+	NotBefore string `json:"notBefore,omitempty"`
+	NotAfter  string `json:"notAfter,omitempty"`
+}
+
+// formatTime formats a time, keeping the zero time empty.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// parseTime parses a time, keeping empty strings as the zero time.
+func parseTime(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// Store manages the rings saved in a configuration directory.
+type Store struct {
+	dir string
+}
+
+// New creates a store in the given directory.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Create creates a new named ring.
+func (s *Store) Create(name string, keys []ring.PublicKey) (*Ring, error) {
+	if !validName.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+
+	if _, err := os.Stat(s.path(name)); err == nil {
+		return nil, ErrRingExists
+	}
+
+	r := &Ring{Name: name}
+	err := r.Add(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Save(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Load loads a named ring.
+func (s *Store) Load(name string) (*Ring, error) {
+	if !validName.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+
+	b, err := ioutil.ReadFile(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrRingNotFound
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var f ringFile
+	err = json.Unmarshal(b, &f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ring file for %s", name)
+	}
+
+	r := &Ring{Name: name, Keys: make([]ring.PublicKey, len(f.Keys))}
+	for i, k := range f.Keys {
+		pk, err := ring.ConfigDecodeKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key in ring %s", name)
+		}
+
+		if _, err := ring.PublicKey(pk).Fingerprint(); err != nil {
+			return nil, errors.Wrapf(err, "invalid key in ring %s", name)
+		}
+
+		r.Keys[i] = pk
+	}
+
+	for _, v := range f.Validity {
+		pk, err := ring.ConfigDecodeKey(v.Key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key in ring %s", name)
+		}
+
+		notBefore, err := parseTime(v.NotBefore)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid validity in ring %s", name)
+		}
+
+		notAfter, err := parseTime(v.NotAfter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid validity in ring %s", name)
+		}
+
+		err = r.SetValidity(pk, notBefore, notAfter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid validity in ring %s", name)
+		}
+	}
+
+	return r, nil
+}
+
+// Save saves a named ring, overwriting any previous version.
+func (s *Store) Save(r *Ring) error {
+	if !validName.MatchString(r.Name) {
+		return ErrInvalidName
+	}
+
+	f := ringFile{Name: r.Name, Keys: make([]string, len(r.Keys))}
+	for i, k := range r.Keys {
+		f.Keys[i] = ring.ConfigEncodeKey(k)
+	}
+
+	for _, d := range r.Descriptors() {
+		if d.NotBefore.IsZero() && d.NotAfter.IsZero() {
+			continue
+		}
+
+		f.Validity = append(f.Validity, validityFile{
+			Key:       ring.ConfigEncodeKey(d.PublicKey),
+			NotBefore: formatTime(d.NotBefore),
+			NotAfter:  formatTime(d.NotAfter),
+		})
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.MkdirAll(filepath.Join(s.dir, ringsDir), 0700)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(s.path(r.Name), b, 0600))
+}
+
+// Lookup finds the member of any saved ring matching the given encoded
+// public key or fingerprint prefix.
+func (s *Store) Lookup(keyOrPrefix string) (ring.PublicKey, error) {
+	names, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found ring.PublicKey
+	for _, name := range names {
+		r, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+
+		pk, err := r.Lookup(keyOrPrefix)
+		if err == ErrKeyNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if found != nil && string(found) != string(pk) {
+			return nil, ErrAmbiguousKey
+		}
+
+		found = pk
+	}
+
+	if found == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return found, nil
+}
+
+// List lists the names of the saved rings in alphabetical order.
+func (s *Store) List() ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, ringsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var names []string
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), ".json")
+		if !f.IsDir() && name != f.Name() && validName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// AcceptRevocationList records a verified revocation list as the last one
+// accepted from its issuer.
+// It returns ring.ErrStaleRevocationList if the list is older than the one
+// previously accepted, which prevents an attacker from replaying an old list
+// to hide recent revocations.
+func (s *Store) AcceptRevocationList(rl *ring.RevocationList) error {
+	issuer := rl.Issuer()
+	f, err := issuer.Fingerprint()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, revocationsDir, hex.EncodeToString(f[:])+".json")
+	b, err := ioutil.ReadFile(path)
+	if err == nil {
+		last := &ring.RevocationList{}
+		if err := last.Unmarshal(b); err != nil {
+			return errors.Wrapf(err, "invalid revocation list for %s", f)
+		}
+
+		if err := last.Verify(issuer); err != nil {
+			return errors.Wrapf(err, "invalid revocation list for %s", f)
+		}
+
+		if err := rl.CheckNewer(last); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+
+	b, err = rl.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Join(s.dir, revocationsDir), 0700)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(path, b, 0600))
+}
+
+// path returns the path of the file storing the given ring.
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, ringsDir, name+".json")
+}
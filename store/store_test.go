@@ -0,0 +1,198 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring-store")
+	assert.NoError(t, err, "ioutil.TempDir()")
+	defer os.RemoveAll(dir)
+
+	s := New(dir)
+
+	alicePub, _ := ring.Generate(nil)
+	bobPub, _ := ring.Generate(nil)
+	carolPub, _ := ring.Generate(nil)
+
+	t.Run("Lists no rings", func(t *testing.T) {
+		names, err := s.List()
+		assert.NoError(t, err, "List()")
+		assert.Empty(t, names)
+	})
+
+	t.Run("Rejects invalid names", func(t *testing.T) {
+		_, err := s.Create("../team", nil)
+		assert.Equal(t, ErrInvalidName, err)
+
+		_, err = s.Load("")
+		assert.Equal(t, ErrInvalidName, err)
+	})
+
+	t.Run("Creates and loads ring", func(t *testing.T) {
+		_, err := s.Create("team", []ring.PublicKey{alicePub, bobPub})
+		assert.NoError(t, err, "Create()")
+
+		r, err := s.Load("team")
+		assert.NoError(t, err, "Load()")
+		assert.Equal(t, "team", r.Name)
+		assert.EqualValues(t, []ring.PublicKey{alicePub, bobPub}, r.Keys)
+		assert.Equal(t, 1, r.Index(bobPub))
+		assert.Equal(t, -1, r.Index(carolPub))
+	})
+
+	t.Run("Rejects invalid keys", func(t *testing.T) {
+		_, err := s.Create("invalid", []ring.PublicKey{alicePub, ring.PublicKey("not a key")})
+		assert.Equal(t, ring.ErrInvalidPublicKey, err)
+
+		_, err = s.Load("invalid")
+		assert.Equal(t, ErrRingNotFound, err)
+
+		// Corrupted ring files are rejected when loading them.
+		corrupted := `{"name": "corrupted", "keys": ["bm90IGEga2V5"]}`
+		assert.NoError(t, ioutil.WriteFile(s.path("corrupted"), []byte(corrupted), 0600))
+		defer os.Remove(s.path("corrupted"))
+
+		_, err = s.Load("corrupted")
+		assert.Equal(t, ring.ErrInvalidPublicKey, errors.Cause(err))
+	})
+
+	t.Run("Rejects existing ring", func(t *testing.T) {
+		_, err := s.Create("team", nil)
+		assert.Equal(t, ErrRingExists, err)
+	})
+
+	t.Run("Rejects missing ring", func(t *testing.T) {
+		_, err := s.Load("nope")
+		assert.Equal(t, ErrRingNotFound, err)
+	})
+
+	t.Run("Adds and removes keys", func(t *testing.T) {
+		r, err := s.Load("team")
+		assert.NoError(t, err, "Load()")
+
+		assert.Equal(t, ErrDuplicateKey, r.Add(alicePub))
+		assert.Equal(t, ring.ErrInvalidPublicKey, r.Add(ring.PublicKey("not a key")))
+		assert.NoError(t, r.Add(carolPub), "Add()")
+		keys := r.Keys
+		assert.NoError(t, r.Remove(alicePub), "Remove()")
+		assert.Equal(t, ErrKeyNotFound, r.Remove(alicePub))
+		assert.EqualValues(t, []ring.PublicKey{alicePub, bobPub, carolPub}, keys)
+		assert.NoError(t, s.Save(r), "Save()")
+
+		r, err = s.Load("team")
+		assert.NoError(t, err, "Load()")
+		assert.EqualValues(t, []ring.PublicKey{bobPub, carolPub}, r.Keys)
+	})
+
+	t.Run("Looks up keys by fingerprint", func(t *testing.T) {
+		r, err := s.Load("team")
+		assert.NoError(t, err, "Load()")
+
+		f, err := carolPub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		pk, err := r.Lookup(f.ShortID())
+		assert.NoError(t, err, "Lookup()")
+		assert.EqualValues(t, carolPub, pk)
+
+		pk, err = r.Lookup(ring.ConfigEncodeKey(bobPub))
+		assert.NoError(t, err, "Lookup()")
+		assert.EqualValues(t, bobPub, pk)
+
+		_, err = r.Lookup("")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		af, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		_, err = r.Lookup(af.String())
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("Stores validity periods", func(t *testing.T) {
+		r, err := s.Load("team")
+		assert.NoError(t, err, "Load()")
+
+		notBefore := time.Unix(1538000000, 0).UTC()
+		notAfter := notBefore.AddDate(1, 0, 0)
+		assert.Equal(t, ErrKeyNotFound, r.SetValidity(alicePub, notBefore, notAfter))
+		assert.Equal(t, ErrInvalidValidity, r.SetValidity(carolPub, notAfter, notBefore))
+		assert.NoError(t, r.SetValidity(carolPub, notBefore, notAfter), "SetValidity()")
+		assert.NoError(t, s.Save(r), "Save()")
+
+		r, err = s.Load("team")
+		assert.NoError(t, err, "Load()")
+		assert.Equal(t, []ring.KeyDescriptor{
+			{PublicKey: bobPub},
+			{PublicKey: carolPub, NotBefore: notBefore, NotAfter: notAfter},
+		}, r.Descriptors())
+
+		assert.NoError(t, r.Remove(carolPub), "Remove()")
+		assert.NoError(t, r.Add(carolPub), "Add()")
+		assert.Equal(t, ring.KeyDescriptor{PublicKey: carolPub}, r.Descriptors()[1])
+	})
+
+	t.Run("Looks up keys in every ring", func(t *testing.T) {
+		_, err := s.Create("board", []ring.PublicKey{alicePub, carolPub})
+		assert.NoError(t, err, "Create()")
+
+		af, err := alicePub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		pk, err := s.Lookup(af.ShortID())
+		assert.NoError(t, err, "Lookup()")
+		assert.EqualValues(t, alicePub, pk)
+
+		cf, err := carolPub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		pk, err = s.Lookup(cf.String())
+		assert.NoError(t, err, "Lookup()")
+		assert.EqualValues(t, carolPub, pk)
+
+		_, err = s.Lookup("")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("Rejects replayed revocation lists", func(t *testing.T) {
+		_, issuerPriv := ring.Generate(nil)
+		bobFingerprint, err := bobPub.Fingerprint()
+		assert.NoError(t, err, "Fingerprint()")
+
+		old := &ring.RevocationList{}
+		assert.NoError(t, old.Sign(nil, issuerPriv), "Sign()")
+		newer := &ring.RevocationList{Sequence: old.Sequence}
+		newer.Revoke(bobFingerprint, time.Now(), "laptop stolen")
+		assert.NoError(t, newer.Sign(nil, issuerPriv), "Sign()")
+
+		assert.NoError(t, s.AcceptRevocationList(old))
+		assert.NoError(t, s.AcceptRevocationList(newer))
+		assert.NoError(t, s.AcceptRevocationList(newer))
+		assert.Equal(t, ring.ErrStaleRevocationList, s.AcceptRevocationList(old))
+
+		// Lists from other issuers are tracked separately.
+		_, otherPriv := ring.Generate(nil)
+		other := &ring.RevocationList{}
+		assert.NoError(t, other.Sign(nil, otherPriv), "Sign()")
+		assert.NoError(t, s.AcceptRevocationList(other))
+
+		assert.Equal(t, ring.ErrInvalidPublicKey, s.AcceptRevocationList(&ring.RevocationList{}))
+	})
+
+	t.Run("Lists rings", func(t *testing.T) {
+		_, err := s.Create("friends", nil)
+		assert.NoError(t, err, "Create()")
+
+		names, err := s.List()
+		assert.NoError(t, err, "List()")
+		assert.Equal(t, []string{"board", "friends", "team"}, names)
+	})
+}
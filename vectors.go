@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/t-bast/ring-signatures/vectors"
+	"github.com/urfave/cli"
+)
+
+var vectorsCommand = cli.Command{
+	Name:  "vectors",
+	Usage: "generate and check known-answer test vectors",
+	Subcommands: []cli.Command{
+		{
+			Name:      "generate",
+			Usage:     "generate the corpus of test vectors",
+			UsageText: "ring-signatures vectors generate --output vectors.json",
+			Action:    vectorsGenerate,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "file to write the vectors to (standard output when omitted)",
+				},
+			},
+		},
+		{
+			Name:      "check",
+			Usage:     "check test vectors against this implementation",
+			UsageText: "ring-signatures vectors check --file vectors.json",
+			Action:    vectorsCheck,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "file containing the vectors to check",
+				},
+			},
+		},
+	},
+}
+
+func vectorsGenerate(c *cli.Context) error {
+	corpus, err := vectors.Corpus()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	b, err := json.MarshalIndent(corpus, "", "  ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	b = append(b, '\n')
+
+	output := c.String("output")
+	if len(output) == 0 {
+		fmt.Print(string(b))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(output, b, 0644); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("Wrote %d vectors to %s.\n", len(corpus), output)
+
+	return nil
+}
+
+func vectorsCheck(c *cli.Context) error {
+	file := c.String("file")
+	if len(file) == 0 {
+		return cli.NewExitError("you need to specify the file containing the vectors", 1)
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var vs []*vectors.Vector
+	if err := json.Unmarshal(b, &vs); err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid vectors file: %s", err), 1)
+	}
+
+	failed := 0
+	for _, v := range vs {
+		if err := v.Check(); err != nil {
+			fmt.Printf("FAIL %s: %s\n", v.Name, err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("ok   %s\n", v.Name)
+	}
+
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d vectors failed", failed, len(vs)), 1)
+	}
+
+	fmt.Printf("All %d vectors passed.\n", len(vs))
+
+	return nil
+}
@@ -0,0 +1,237 @@
+// Package vectors implements known-answer test vectors for ring signatures.
+//
+// A vector contains everything needed to reproduce a signature byte for
+// byte: the private keys, the ring, the message and the randomness consumed
+// by the signer. Other implementations can use them to check that they
+// produce and accept the same signatures as this one.
+//
+// Keys and randomness are derived from the vector's seed with SHA256 in
+// counter mode: block i of the stream labelled l is SHA256(seed || l || i),
+// with i a big-endian uint64. Scalars are read from a stream like Go's
+// crypto/rand.Int does: 48 bytes interpreted in big-endian, rejected if
+// they're not in [1:N-1] (where N is the order of P-384).
+//
+// The signer consumes one scalar for its nonce k, then one scalar s(i) for
+// each other ring member, starting after the signer and wrapping around.
+package vectors
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+var (
+	// ErrInvalidVector is returned when a vector is malformed.
+	ErrInvalidVector = errors.New("invalid test vector")
+
+	// ErrKeyMismatch is returned when a ring member isn't derived from the
+	// matching private key.
+	ErrKeyMismatch = errors.New("the ring doesn't match the private keys")
+
+	// ErrSignatureMismatch is returned when signing doesn't produce the
+	// expected signature.
+	ErrSignatureMismatch = errors.New("the signature doesn't match the expected signature")
+)
+
+// Vector is a known-answer test vector.
+// Binary fields are hex-encoded, keys and signatures use the same encoding
+// as the command line tool.
+type Vector struct {
+	Name        string   `json:"name"`
+	Seed        string   `json:"seed"`
+	PrivateKeys []string `json:"privateKeys"`
+	Ring        []string `json:"ring"`
+	SignerIndex int      `json:"signerIndex"`
+	Message     string   `json:"message"`
+	Randomness  string   `json:"randomness"`
+	Signature   string   `json:"signature"`
+}
+
+// stream is a deterministic stream of bytes derived from a seed.
+type stream struct {
+	seed    []byte
+	label   string
+	counter uint64
+	buf     []byte
+	read    []byte
+}
+
+// Read implements io.Reader and records the bytes read.
+func (s *stream) Read(p []byte) (int, error) {
+	for len(s.buf) < len(p) {
+		h := sha256.New()
+		h.Write(s.seed)
+		h.Write([]byte(s.label))
+		binary.Write(h, binary.BigEndian, s.counter)
+		s.counter++
+		s.buf = h.Sum(s.buf)
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	s.read = append(s.read, p[:n]...)
+	return n, nil
+}
+
+// privateKey derives a private key from a stream.
+func privateKey(s io.Reader) (ring.PrivateKey, error) {
+	n := elliptic.P384().Params().N
+	for {
+		x, err := rand.Int(s, n)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if x.Sign() == 1 {
+			sk := make([]byte, (n.BitLen()+7)/8)
+			b := x.Bytes()
+			copy(sk[len(sk)-len(b):], b)
+			return ring.PrivateKey(sk), nil
+		}
+	}
+}
+
+// Generate generates a vector from a seed.
+func Generate(name string, seed []byte, ringSize, signerIndex int, message []byte) (*Vector, error) {
+	v := &Vector{
+		Name:        name,
+		Seed:        hex.EncodeToString(seed),
+		SignerIndex: signerIndex,
+		Message:     hex.EncodeToString(message),
+	}
+
+	if signerIndex < 0 || ringSize <= signerIndex {
+		return nil, ring.ErrInvalidSignerIndex
+	}
+
+	privKeys := make([]ring.PrivateKey, ringSize)
+	ringKeys := make([]ring.PublicKey, ringSize)
+	for i := range privKeys {
+		sk, err := privateKey(&stream{seed: seed, label: fmt.Sprintf("key/%d", i)})
+		if err != nil {
+			return nil, err
+		}
+
+		privKeys[i] = sk
+		ringKeys[i] = sk.Public()
+		v.PrivateKeys = append(v.PrivateKeys, ring.ConfigEncodeKey(sk))
+		v.Ring = append(v.Ring, ring.ConfigEncodeKey(ringKeys[i]))
+	}
+
+	randomness := &stream{seed: seed, label: "sign"}
+	sig, err := privKeys[signerIndex].Sign(randomness, message, ringKeys, signerIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Randomness = hex.EncodeToString(randomness.read)
+	v.Signature, err = sig.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Check checks that the vector's ring matches its private keys, that
+// signing with its randomness produces the expected signature and that the
+// signature is valid.
+func (v *Vector) Check() error {
+	if len(v.PrivateKeys) != len(v.Ring) || v.SignerIndex < 0 || len(v.Ring) <= v.SignerIndex {
+		return ErrInvalidVector
+	}
+
+	ringKeys := make([]ring.PublicKey, len(v.Ring))
+	for i := range v.Ring {
+		pk, err := ring.ConfigDecodeKey(v.Ring[i])
+		if err != nil {
+			return errors.Wrapf(ErrInvalidVector, "ring member %d", i)
+		}
+
+		sk, err := ring.ConfigDecodeKey(v.PrivateKeys[i])
+		if err != nil {
+			return errors.Wrapf(ErrInvalidVector, "private key %d", i)
+		}
+
+		if !bytes.Equal(ring.PrivateKey(sk).Public(), pk) {
+			return errors.Wrapf(ErrKeyMismatch, "ring member %d", i)
+		}
+
+		ringKeys[i] = pk
+	}
+
+	message, err := hex.DecodeString(v.Message)
+	if err != nil {
+		return errors.Wrap(ErrInvalidVector, "message")
+	}
+
+	randomness, err := hex.DecodeString(v.Randomness)
+	if err != nil {
+		return errors.Wrap(ErrInvalidVector, "randomness")
+	}
+
+	sk, _ := ring.ConfigDecodeKey(v.PrivateKeys[v.SignerIndex])
+	sig, err := ring.PrivateKey(sk).Sign(bytes.NewReader(randomness), message, ringKeys, v.SignerIndex)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := sig.Encode()
+	if err != nil {
+		return err
+	}
+
+	if encoded != v.Signature {
+		return ErrSignatureMismatch
+	}
+
+	expected := &ring.Signature{}
+	if err := expected.Decode(v.Signature); err != nil || !expected.Verify(message) {
+		return ring.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Corpus generates the corpus of vectors shipped with the repository.
+// It is deterministic: vectors only change when the signature format or
+// algorithm changes.
+func Corpus() ([]*Vector, error) {
+	longMessage := bytes.Repeat([]byte("ring signatures "), 64)
+	params := []struct {
+		name        string
+		ringSize    int
+		signerIndex int
+		message     []byte
+	}{
+		{"two members, first signs", 2, 0, []byte("hello")},
+		{"two members, last signs", 2, 1, []byte("hello")},
+		{"three members, middle signs", 3, 1, []byte("Benchmark me like the french people do.")},
+		{"five members, binary message", 5, 3, []byte{0x00, 0x01, 0xfe, 0xff}},
+		{"five members, single byte message", 5, 4, []byte{0x2a}},
+		{"ten members, long message", 10, 7, longMessage},
+		{"sixteen members, utf-8 message", 16, 0, []byte("anonymität für alle ✓")},
+	}
+
+	var corpus []*Vector
+	for i, p := range params {
+		seed := sha256.Sum256([]byte(fmt.Sprintf("ring-signatures/vectors/%d", i)))
+		v, err := Generate(p.name, seed[:], p.ringSize, p.signerIndex, p.message)
+		if err != nil {
+			return nil, err
+		}
+
+		corpus = append(corpus, v)
+	}
+
+	return corpus, nil
+}
@@ -0,0 +1,69 @@
+package vectors
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+func TestVectors(t *testing.T) {
+	t.Run("Generates deterministic vectors", func(t *testing.T) {
+		v1, err := Generate("test", []byte("seed"), 3, 1, []byte("hello"))
+		assert.NoError(t, err, "Generate()")
+		assert.NoError(t, v1.Check(), "Check()")
+
+		v2, err := Generate("test", []byte("seed"), 3, 1, []byte("hello"))
+		assert.NoError(t, err, "Generate()")
+		assert.Equal(t, v1, v2)
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		_, err := Generate("test", []byte("seed"), 3, 3, []byte("hello"))
+		assert.EqualError(t, err, ring.ErrInvalidSignerIndex.Error())
+
+		_, err = Generate("test", []byte("seed"), 1, 0, []byte("hello"))
+		assert.EqualError(t, err, ring.ErrRingTooSmall.Error())
+	})
+
+	t.Run("Detects mismatches", func(t *testing.T) {
+		v, err := Generate("test", []byte("seed"), 3, 2, []byte("hello"))
+		assert.NoError(t, err, "Generate()")
+
+		tampered := *v
+		tampered.Ring = []string{v.Ring[1], v.Ring[0], v.Ring[2]}
+		assert.Equal(t, ErrKeyMismatch, errors.Cause(tampered.Check()))
+
+		tampered = *v
+		tampered.Message = "00"
+		assert.Equal(t, ErrSignatureMismatch, tampered.Check())
+
+		tampered = *v
+		tampered.Randomness = "not hex"
+		assert.Equal(t, ErrInvalidVector, errors.Cause(tampered.Check()))
+
+		tampered = *v
+		tampered.SignerIndex = 3
+		assert.Equal(t, ErrInvalidVector, tampered.Check())
+	})
+
+	t.Run("Checks the shipped corpus", func(t *testing.T) {
+		b, err := ioutil.ReadFile("testdata/vectors.json")
+		assert.NoError(t, err, "ioutil.ReadFile()")
+
+		var shipped []*Vector
+		assert.NoError(t, json.Unmarshal(b, &shipped), "json.Unmarshal()")
+		assert.NotEmpty(t, shipped)
+
+		for _, v := range shipped {
+			assert.NoError(t, v.Check(), v.Name)
+		}
+
+		corpus, err := Corpus()
+		assert.NoError(t, err, "Corpus()")
+		assert.Equal(t, shipped, corpus)
+	})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	crand "crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/t-bast/ring-signatures/ring"
+	"github.com/t-bast/ring-signatures/vote"
+	"github.com/urfave/cli"
+)
+
+var voteCommand = cli.Command{
+	Name:  "vote",
+	Usage: "run anonymous votes",
+	Subcommands: []cli.Command{
+		{
+			Name:  "create",
+			Usage: "create a ballot for the members of a ring",
+			UsageText: "ring-signatures vote create --id q3-2018 --question \"Pizza on fridays?\"" +
+				" --option yes --option no --ring-name team > ballot.json",
+			Action: voteCreate,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "id",
+					Usage: "unique identifier of the ballot",
+				},
+				cli.StringFlag{
+					Name:  "question, q",
+					Usage: "question asked to the voters",
+				},
+				cli.StringSliceFlag{
+					Name:  "option, o",
+					Usage: "option voters can choose",
+				},
+				cli.StringSliceFlag{
+					Name:  "ring, r",
+					Usage: "public keys of the eligible voters",
+				},
+				cli.StringFlag{
+					Name:  "ring-jwks",
+					Usage: "JSON Web Key Set file containing the public keys of the eligible voters",
+				},
+				cli.StringFlag{
+					Name:  "ring-name, n",
+					Usage: "name of a saved ring containing the eligible voters",
+				},
+			},
+		},
+		{
+			Name:      "cast",
+			Usage:     "cast an anonymous vote",
+			UsageText: "ring-signatures vote cast --ballot ballot.json --choice yes --private-key Pr1v4T3k3y >> votes.txt",
+			Action:    voteCast,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "ballot, b",
+					Usage: "ballot definition file",
+				},
+				cli.StringFlag{
+					Name:  "choice, c",
+					Usage: "option you vote for",
+				},
+				cli.StringFlag{
+					Name:  "private-key, k",
+					Usage: "private key to sign your vote with",
+				},
+			},
+		},
+		{
+			Name:      "tally",
+			Usage:     "verify and count votes",
+			UsageText: "ring-signatures vote tally --ballot ballot.json --votes votes.txt",
+			Action:    voteTally,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "ballot, b",
+					Usage: "ballot definition file",
+				},
+				cli.StringFlag{
+					Name:  "votes",
+					Usage: "file containing one vote per line",
+				},
+			},
+		},
+	},
+}
+
+// readBallot reads the ballot definition file given in the command flags.
+func readBallot(c *cli.Context) (*vote.Ballot, error) {
+	ballotFile := c.String("ballot")
+	if len(ballotFile) == 0 {
+		return nil, cli.NewExitError("you need to specify the ballot", 1)
+	}
+
+	b, err := ioutil.ReadFile(ballotFile)
+	if err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	ballot := &vote.Ballot{}
+	err = json.Unmarshal(b, ballot)
+	if err != nil {
+		return nil, cli.NewExitError(fmt.Sprintf("invalid ballot: %s", err), 1)
+	}
+
+	err = ballot.Validate()
+	if err != nil {
+		return nil, cli.NewExitError(err, 1)
+	}
+
+	return ballot, nil
+}
+
+func voteCreate(c *cli.Context) error {
+	voters, err := ringFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	ballot, err := vote.NewBallot(c.String("id"), c.String("question"), c.StringSlice("option"), voters)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	b, err := json.MarshalIndent(ballot, "", "  ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
+
+func voteCast(c *cli.Context) error {
+	ballot, err := readBallot(c)
+	if err != nil {
+		return err
+	}
+
+	sk, err := ring.ConfigDecodeKey(c.String("private-key"))
+	if err != nil || len(sk) == 0 {
+		return cli.NewExitError("invalid private key", 1)
+	}
+
+	v, err := ballot.Cast(crand.Reader, ring.PrivateKey(sk), c.String("choice"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
+
+func voteTally(c *cli.Context) error {
+	ballot, err := readBallot(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(c.String("votes"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	defer f.Close()
+
+	var votes []*vote.Vote
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		v := &vote.Vote{}
+		if json.Unmarshal([]byte(line), v) != nil {
+			v = nil
+		}
+
+		votes = append(votes, v)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	res := ballot.Tally(votes)
+
+	fmt.Println(ballot.Question)
+	for _, o := range ballot.Options {
+		fmt.Printf("   %s: %d\n", o, res.Counts[o])
+	}
+
+	fmt.Printf("%d votes counted, %d rejected.\n", res.Accepted, len(res.Rejected))
+	for _, r := range res.Rejected {
+		fmt.Printf("   vote %d: %s\n", r.Index+1, r.Reason)
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+// Package vote implements anonymous voting with linkable ring signatures.
+//
+// An organizer publishes a ballot listing the options and the public keys
+// of the eligible voters. Each voter signs their choice with a linkable
+// ring signature over the eligible voters: nobody can tell who voted for
+// what, but a voter who votes twice produces the same key image. Votes for
+// the same choice are counted once, and if a voter voted for different
+// choices all their votes are rejected during the tally.
+package vote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+var (
+	// ErrInvalidBallot is returned when a ballot definition is malformed.
+	ErrInvalidBallot = errors.New("invalid ballot")
+
+	// ErrInvalidChoice is returned when a vote is for an unknown option.
+	ErrInvalidChoice = errors.New("the choice is not one of the ballot options")
+
+	// ErrWrongBallot is returned when a vote was cast for another ballot.
+	ErrWrongBallot = errors.New("the vote was cast for another ballot")
+
+	// ErrNotEligible is returned when a vote's ring isn't the ballot's
+	// eligible voters.
+	ErrNotEligible = errors.New("the vote was not signed by the eligible voters")
+
+	// ErrInvalidVoteSignature is returned when a vote's signature is invalid.
+	ErrInvalidVoteSignature = errors.New("the vote signature is invalid")
+
+	// ErrDoubleVote is returned when a voter voted for different choices.
+	ErrDoubleVote = errors.New("the voter voted more than once")
+
+	// ErrDuplicateVote is returned when a voter's vote for the same choice
+	// was submitted twice, for example when a vote is replayed.
+	ErrDuplicateVote = errors.New("the vote was already counted")
+)
+
+// scopePrefix separates voting key images from other linkable signatures.
+const scopePrefix = "ring-signatures/vote/v1"
+
+// Ballot is the definition of a vote, published by the organizer.
+type Ballot struct {
+	ID       string           `json:"id"`
+	Question string           `json:"question"`
+	Options  []string         `json:"options"`
+	Voters   []ring.PublicKey `json:"voters"`
+}
+
+// NewBallot creates a ballot.
+// The eligible voters are put in canonical order so that every voter
+// signs with the same ring.
+func NewBallot(id, question string, options []string, voters []ring.PublicKey) (*Ballot, error) {
+	b := &Ballot{
+		ID:       id,
+		Question: question,
+		Options:  options,
+		Voters:   ring.CanonicalRing(voters),
+	}
+
+	err := b.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Validate checks that the ballot is well-formed.
+func (b *Ballot) Validate() error {
+	if len(b.ID) == 0 {
+		return errors.Wrap(ErrInvalidBallot, "missing id")
+	}
+
+	if len(b.Options) < 2 {
+		return errors.Wrap(ErrInvalidBallot, "at least two options are needed")
+	}
+
+	seen := make(map[string]bool)
+	for _, o := range b.Options {
+		if len(o) == 0 || seen[o] {
+			return errors.Wrap(ErrInvalidBallot, "options should be unique and non-empty")
+		}
+
+		seen[o] = true
+	}
+
+	if len(b.Voters) < 2 {
+		return errors.Wrap(ErrInvalidBallot, "at least two voters are needed")
+	}
+
+	if len(b.Voters) > ring.MaxRingSize {
+		return errors.Wrapf(ErrInvalidBallot, "at most %d voters are supported", ring.MaxRingSize)
+	}
+
+	if !ring.IsCanonicalRing(b.Voters) {
+		return errors.Wrap(ErrInvalidBallot, "voters should be in canonical order")
+	}
+
+	return nil
+}
+
+// Digest returns the hash of the ballot definition.
+// Votes are bound to it, so changing the ballot invalidates them.
+func (b *Ballot) Digest() []byte {
+	encoded, _ := json.Marshal(b)
+	h := sha256.Sum256(encoded)
+	return h[:]
+}
+
+// scope returns the scope of the voters' key images.
+func (b *Ballot) scope() []byte {
+	return append([]byte(scopePrefix), b.Digest()...)
+}
+
+// message returns the signed message of a vote.
+func (b *Ballot) message(choice string) []byte {
+	return append(b.Digest(), []byte(choice)...)
+}
+
+// hasOption returns true if the choice is one of the ballot options.
+func (b *Ballot) hasOption(choice string) bool {
+	for _, o := range b.Options {
+		if o == choice {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Vote is a signed ballot cast by an anonymous voter.
+type Vote struct {
+	Ballot    string `json:"ballot"`
+	Choice    string `json:"choice"`
+	Signature string `json:"signature"`
+}
+
+// Cast casts a vote for the given choice.
+func (b *Ballot) Cast(rand io.Reader, sk ring.PrivateKey, choice string) (*Vote, error) {
+	if !b.hasOption(choice) {
+		return nil, ErrInvalidChoice
+	}
+
+	signerIndex, err := sk.SignerIndex(b.Voters)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := sk.SignLinkable(rand, b.message(choice), b.Voters, signerIndex, b.scope())
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := sig.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Vote{
+		Ballot:    hex.EncodeToString(b.Digest()),
+		Choice:    choice,
+		Signature: encoded,
+	}, nil
+}
+
+// Check verifies a vote and returns its signature.
+// It doesn't detect double votes: see Tally.
+func (b *Ballot) Check(v *Vote) (*ring.LinkableSignature, error) {
+	if v.Ballot != hex.EncodeToString(b.Digest()) {
+		return nil, ErrWrongBallot
+	}
+
+	if !b.hasOption(v.Choice) {
+		return nil, ErrInvalidChoice
+	}
+
+	// Only the canonical encoding is accepted, so that a published vote
+	// can't be submitted again under another encoding.
+	opts := ring.DefaultDecodeOptions
+	opts.StrictEncoding = true
+
+	sig := &ring.LinkableSignature{}
+	err := sig.DecodeWithOptions(v.Signature, opts)
+	if err != nil {
+		return nil, ErrInvalidVoteSignature
+	}
+
+	if !bytes.Equal(sig.Scope(), b.scope()) {
+		return nil, ErrWrongBallot
+	}
+
+	if !ring.NewKeySet(sig.Ring()...).Equal(ring.NewKeySet(b.Voters...)) {
+		return nil, ErrNotEligible
+	}
+
+	if !sig.Verify(b.message(v.Choice)) {
+		return nil, ErrInvalidVoteSignature
+	}
+
+	return sig, nil
+}
+
+// Rejection explains why a vote was not counted.
+type Rejection struct {
+	// Index is the position of the vote in the tallied votes.
+	Index  int
+	Reason error
+}
+
+// Result is the outcome of a tally.
+type Result struct {
+	// Counts contains the number of votes for each option.
+	Counts map[string]int
+	// Accepted is the number of counted votes.
+	Accepted int
+	// Rejected lists the votes that were not counted.
+	Rejected []Rejection
+}
+
+// Tally verifies and counts votes.
+// Votes sharing a key image come from the same voter: when they are all for
+// the same choice, it is counted once and the others are duplicates. When a
+// voter voted for different choices, none of their votes are counted.
+func (b *Ballot) Tally(votes []*Vote) *Result {
+	res := &Result{Counts: make(map[string]int)}
+	for _, o := range b.Options {
+		res.Counts[o] = 0
+	}
+
+	var keyImages []string
+	byKeyImage := make(map[string][]int)
+	for i, v := range votes {
+		if v == nil {
+			res.Rejected = append(res.Rejected, Rejection{Index: i, Reason: ErrInvalidVoteSignature})
+			continue
+		}
+
+		sig, err := b.Check(v)
+		if err != nil {
+			res.Rejected = append(res.Rejected, Rejection{Index: i, Reason: err})
+			continue
+		}
+
+		keyImage := string(sig.KeyImage())
+		if _, ok := byKeyImage[keyImage]; !ok {
+			keyImages = append(keyImages, keyImage)
+		}
+
+		byKeyImage[keyImage] = append(byKeyImage[keyImage], i)
+	}
+
+	for _, keyImage := range keyImages {
+		indexes := byKeyImage[keyImage]
+		choice := votes[indexes[0]].Choice
+
+		reason := ErrDuplicateVote
+		for _, i := range indexes[1:] {
+			if votes[i].Choice != choice {
+				reason = ErrDoubleVote
+			}
+		}
+
+		if reason == ErrDoubleVote {
+			for _, i := range indexes {
+				res.Rejected = append(res.Rejected, Rejection{Index: i, Reason: ErrDoubleVote})
+			}
+
+			continue
+		}
+
+		for _, i := range indexes[1:] {
+			res.Rejected = append(res.Rejected, Rejection{Index: i, Reason: ErrDuplicateVote})
+		}
+
+		res.Counts[choice]++
+		res.Accepted++
+	}
+
+	sort.Slice(res.Rejected, func(i, j int) bool {
+		return res.Rejected[i].Index < res.Rejected[j].Index
+	})
+
+	return res
+}
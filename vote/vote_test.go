@@ -0,0 +1,136 @@
+package vote
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-bast/ring-signatures/ring"
+)
+
+func TestBallot(t *testing.T) {
+	alicePub, _ := ring.Generate(nil)
+	bobPub, _ := ring.Generate(nil)
+
+	t.Run("Validates ballot", func(t *testing.T) {
+		_, err := NewBallot("", "?", []string{"yes", "no"}, []ring.PublicKey{alicePub, bobPub})
+		assert.Equal(t, ErrInvalidBallot, errors.Cause(err))
+
+		_, err = NewBallot("q1", "?", []string{"yes"}, []ring.PublicKey{alicePub, bobPub})
+		assert.Equal(t, ErrInvalidBallot, errors.Cause(err))
+
+		_, err = NewBallot("q1", "?", []string{"yes", "yes"}, []ring.PublicKey{alicePub, bobPub})
+		assert.Equal(t, ErrInvalidBallot, errors.Cause(err))
+
+		_, err = NewBallot("q1", "?", []string{"yes", "no"}, []ring.PublicKey{alicePub, alicePub})
+		assert.Equal(t, ErrInvalidBallot, errors.Cause(err))
+
+		b, err := NewBallot("q1", "?", []string{"yes", "no"}, []ring.PublicKey{alicePub, bobPub})
+		assert.NoError(t, err, "NewBallot()")
+		assert.True(t, ring.IsCanonicalRing(b.Voters))
+
+		// Votes couldn't be decoded with larger rings.
+		voters := make([]ring.PublicKey, ring.MaxRingSize+1)
+		for i := range voters {
+			voters[i], _ = ring.Generate(nil)
+		}
+
+		_, err = NewBallot("q1", "?", []string{"yes", "no"}, voters)
+		assert.Equal(t, ErrInvalidBallot, errors.Cause(err))
+	})
+
+	t.Run("Digest depends on the definition", func(t *testing.T) {
+		b1, err := NewBallot("q1", "?", []string{"yes", "no"}, []ring.PublicKey{alicePub, bobPub})
+		assert.NoError(t, err, "NewBallot()")
+
+		b2, err := NewBallot("q1", "?", []string{"yes", "no"}, []ring.PublicKey{bobPub, alicePub})
+		assert.NoError(t, err, "NewBallot()")
+		assert.Equal(t, b1.Digest(), b2.Digest())
+
+		b3, err := NewBallot("q2", "?", []string{"yes", "no"}, []ring.PublicKey{alicePub, bobPub})
+		assert.NoError(t, err, "NewBallot()")
+		assert.NotEqual(t, b1.Digest(), b3.Digest())
+	})
+}
+
+func TestTally(t *testing.T) {
+	alicePub, alicePriv := ring.Generate(nil)
+	bobPub, bobPriv := ring.Generate(nil)
+	carolPub, carolPriv := ring.Generate(nil)
+	davePub, davePriv := ring.Generate(nil)
+	_, evePriv := ring.Generate(nil)
+
+	b, err := NewBallot("q3", "Pizza on fridays?", []string{"yes", "no"}, []ring.PublicKey{alicePub, bobPub, carolPub, davePub})
+	assert.NoError(t, err, "NewBallot()")
+
+	other, err := NewBallot("q4", "Pizza on mondays?", []string{"yes", "no"}, []ring.PublicKey{alicePub, bobPub})
+	assert.NoError(t, err, "NewBallot()")
+
+	cast := func(sk ring.PrivateKey, choice string) *Vote {
+		v, err := b.Cast(nil, sk, choice)
+		assert.NoError(t, err, "Cast()")
+		return v
+	}
+
+	t.Run("Rejects invalid votes", func(t *testing.T) {
+		_, err := b.Cast(nil, alicePriv, "maybe")
+		assert.Equal(t, ErrInvalidChoice, err)
+
+		_, err = b.Cast(nil, evePriv, "yes")
+		assert.Equal(t, ring.ErrSignerNotInRing, err)
+
+		v := cast(alicePriv, "yes")
+		v.Choice = "no"
+		_, err = b.Check(v)
+		assert.Equal(t, ErrInvalidVoteSignature, err)
+
+		otherVote, err := other.Cast(nil, alicePriv, "yes")
+		assert.NoError(t, err, "Cast()")
+		_, err = b.Check(otherVote)
+		assert.Equal(t, ErrWrongBallot, err)
+	})
+
+	t.Run("Counts votes", func(t *testing.T) {
+		aliceVote := cast(alicePriv, "yes")
+		votes := []*Vote{
+			aliceVote,
+			cast(bobPriv, "no"),
+			cast(carolPriv, "yes"),
+			cast(davePriv, "yes"),
+			cast(davePriv, "no"),
+			aliceVote,
+			{Ballot: aliceVote.Ballot, Choice: "yes", Signature: "garbage"},
+		}
+
+		res := b.Tally(votes)
+		assert.Equal(t, map[string]int{"yes": 2, "no": 1}, res.Counts)
+		assert.Equal(t, 3, res.Accepted)
+		assert.Equal(t, []Rejection{
+			{Index: 3, Reason: ErrDoubleVote},
+			{Index: 4, Reason: ErrDoubleVote},
+			{Index: 5, Reason: ErrDuplicateVote},
+			{Index: 6, Reason: ErrInvalidVoteSignature},
+		}, res.Rejected)
+	})
+
+	t.Run("Counts replayed votes once", func(t *testing.T) {
+		aliceVote := cast(alicePriv, "yes")
+
+		decoded, err := base64.StdEncoding.DecodeString(aliceVote.Signature)
+		assert.NoError(t, err, "DecodeString()")
+		reencoded := *aliceVote
+		reencoded.Signature = base64.StdEncoding.EncodeToString(append([]byte(" "), decoded...))
+
+		_, err = b.Check(&reencoded)
+		assert.Equal(t, ErrInvalidVoteSignature, err)
+
+		res := b.Tally([]*Vote{aliceVote, &reencoded, cast(alicePriv, "yes"), cast(bobPriv, "no")})
+		assert.Equal(t, map[string]int{"yes": 1, "no": 1}, res.Counts)
+		assert.Equal(t, 2, res.Accepted)
+		assert.Equal(t, []Rejection{
+			{Index: 1, Reason: ErrInvalidVoteSignature},
+			{Index: 2, Reason: ErrDuplicateVote},
+		}, res.Rejected)
+	})
+}